@@ -0,0 +1,352 @@
+package cve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-version"
+)
+
+// normalizeZeroVersion return "0" for any zero equivalent version (e.g. 0.0, 0.0.0 or v0), other versions are unchanged
+func normalizeZeroVersion(v string) string {
+	trimmed := strings.TrimLeft(strings.TrimSpace(v), "vV")
+	if len(trimmed) == 0 {
+		return v
+	}
+	for _, segment := range strings.Split(trimmed, ".") {
+		if len(segment) == 0 || strings.Trim(segment, "0") != "" {
+			return v
+		}
+	}
+	return "0"
+}
+
+// introducedVersion return the introduced event of the affected first range
+func introducedVersion(a *Affected) string {
+	for _, r := range a.Ranges {
+		for _, e := range r.Events {
+			if len(e.Introduced) > 0 {
+				return e.Introduced
+			}
+		}
+	}
+	return ""
+}
+
+// affectedLess order affected entries by introduced version, unparsable and non semver versions are kept in place
+func affectedLess(a, b *Affected) bool {
+	if ecosystemAffected(a) || ecosystemAffected(b) {
+		return false
+	}
+	v1, err := version.NewVersion(introducedVersion(a))
+	if err != nil {
+		return false
+	}
+	v2, err := version.NewVersion(introducedVersion(b))
+	if err != nil {
+		return false
+	}
+	return v1.LessThan(v2)
+}
+
+// sortAffected sort affected entries by introduced version so output is stable across runs
+func sortAffected(affected []*Affected) {
+	sort.SliceStable(affected, func(i, j int) bool {
+		return affectedLess(affected[i], affected[j])
+	})
+}
+
+// ecosystemAffected check if the affected has a range ordered by its own version scheme and no semver twin
+// (see WithEcosystemRanges) to order it by
+func ecosystemAffected(a *Affected) bool {
+	var eco bool
+	for _, r := range a.Ranges {
+		switch r.RangeType {
+		case ecosystem:
+			eco = true
+		case semver:
+			return false
+		}
+	}
+	return eco
+}
+
+// addEcosystemRanges add to each affected entry the ECOSYSTEM twin of its semver ranges, events carrying the
+// kubernetes release versions (e.g. v1.24.14) for osv consumers keyed to the release tags
+func addEcosystemRanges(v *Vulnerability) {
+	for _, a := range v.Affected {
+		for _, r := range a.Ranges {
+			if r.RangeType != semver {
+				continue
+			}
+			events := make([]*Event, 0, len(r.Events))
+			for _, e := range r.Events {
+				events = append(events, &Event{
+					Introduced:   releaseVersion(e.Introduced),
+					Fixed:        releaseVersion(e.Fixed),
+					LastAffected: releaseVersion(e.LastAffected),
+					Limit:        releaseVersion(e.Limit),
+				})
+			}
+			a.Ranges = append(a.Ranges, &Range{RangeType: ecosystem, Events: events})
+		}
+	}
+}
+
+// releaseVersion return the kubernetes release tag of a semver version, the osv "0" introduced stay as is
+func releaseVersion(v string) string {
+	if len(v) == 0 || v == "0" {
+		return v
+	}
+	return "v" + strings.TrimPrefix(v, "v")
+}
+
+// emptyEvents check if none of the range events carry a version
+func emptyEvents(r *Range) bool {
+	for _, e := range r.Events {
+		if len(e.Introduced) > 0 || len(e.Fixed) > 0 || len(e.LastAffected) > 0 || len(e.Limit) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LatestMinorLine is reported by AffectedMinorLines when a range has no fixed or last affected bound,
+// meaning every line after the latest reported one is affected as well
+const LatestMinorLine = "latest"
+
+type minorLine struct {
+	major int
+	minor int
+}
+
+// AffectedMinorLines return the distinct major.minor lines (e.g. 1.24) covered by the vulnerability ranges, sorted.
+// an introduced "0" bound start at the first line of the range end major, a fixed bound on the first
+// release of a line (e.g. 1.25.0) does not cover that line and an open ended range add LatestMinorLine
+func (v *Vulnerability) AffectedMinorLines() []string {
+	type bounds struct {
+		from, to *minorLine
+	}
+	rangeBounds := make([]bounds, 0)
+	maxMinor := make(map[int]int)
+	track := func(l *minorLine) {
+		if m, ok := maxMinor[l.major]; !ok || l.minor > m {
+			maxMinor[l.major] = l.minor
+		}
+	}
+	var openEnded bool
+	for _, a := range v.Affected {
+		for _, r := range a.Ranges {
+			if r.RangeType == ecosystem {
+				continue
+			}
+			var introduced, fixed, lastAffected string
+			for _, e := range r.Events {
+				switch {
+				case len(e.Introduced) > 0:
+					introduced = e.Introduced
+				case len(e.Fixed) > 0:
+					fixed = e.Fixed
+				case len(e.LastAffected) > 0:
+					lastAffected = e.LastAffected
+				}
+			}
+			to := lineOf(lastAffected, false)
+			if to == nil {
+				to = lineOf(fixed, true)
+			}
+			from := lineOf(introduced, false)
+			if normalizeZeroVersion(introduced) == "0" && to != nil {
+				from = &minorLine{major: to.major}
+			}
+			if from == nil {
+				continue
+			}
+			track(from)
+			if to == nil {
+				openEnded = true
+			} else {
+				track(to)
+			}
+			rangeBounds = append(rangeBounds, bounds{from: from, to: to})
+		}
+	}
+	covered := make(map[minorLine]bool)
+	for _, b := range rangeBounds {
+		to := b.to
+		if to == nil {
+			to = &minorLine{major: b.from.major, minor: maxMinor[b.from.major]}
+		}
+		for major := b.from.major; major <= to.major; major++ {
+			first, last := 0, maxMinor[major]
+			if major == b.from.major {
+				first = b.from.minor
+			}
+			if major == to.major {
+				last = to.minor
+			}
+			for minor := first; minor <= last; minor++ {
+				covered[minorLine{major: major, minor: minor}] = true
+			}
+		}
+	}
+	lines := make([]minorLine, 0, len(covered))
+	for l := range covered {
+		lines = append(lines, l)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].major != lines[j].major {
+			return lines[i].major < lines[j].major
+		}
+		return lines[i].minor < lines[j].minor
+	})
+	result := make([]string, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, fmt.Sprintf("%d.%d", l.major, l.minor))
+	}
+	if openEnded {
+		result = append(result, LatestMinorLine)
+	}
+	return result
+}
+
+// lineOf return the minor line of a version, for an exclusive (fixed) bound on a line first release
+// the previous line is returned
+func lineOf(ver string, exclusive bool) *minorLine {
+	if len(ver) == 0 {
+		return nil
+	}
+	v, err := version.NewVersion(ver)
+	if err != nil {
+		return nil
+	}
+	segments := v.Segments()
+	l := &minorLine{major: segments[0], minor: segments[1]}
+	if exclusive && segments[2] == 0 && v.Prerelease() == "" {
+		if l.minor == 0 {
+			return nil
+		}
+		l.minor--
+	}
+	return l
+}
+
+// AffectingVersion return the vulnerabilities of component (e.g. k8s.io/kubelet or kubelet) whose ranges include ver.
+// a cve whose events can not be compared to ver is skipped, its error returned along the other cves matches
+func (db *K8sVulnDB) AffectingVersion(component, ver string) ([]*Vulnerability, error) {
+	v, err := version.NewVersion(ver)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q: %w", ver, err)
+	}
+	vulnerabilities := make([]*Vulnerability, 0)
+	var result error
+	for _, cve := range db.Cves {
+		if !componentMatch(cve.Component, component) {
+			continue
+		}
+		affected, err := cve.affects(v)
+		if err != nil {
+			result = multierror.Append(result, err)
+			continue
+		}
+		if affected {
+			vulnerabilities = append(vulnerabilities, cve)
+		}
+	}
+	return vulnerabilities, result
+}
+
+// BuildIndex map each component to the sorted ids of the cves affecting it
+func (db *K8sVulnDB) BuildIndex() map[string][]string {
+	index := make(map[string][]string)
+	for _, cve := range db.Cves {
+		index[cve.Component] = append(index[cve.Component], cve.ID)
+	}
+	for _, ids := range index {
+		sort.Strings(ids)
+	}
+	return index
+}
+
+// componentMatch check if cve component is component, either fully qualified or by its repo name
+func componentMatch(cveComponent, component string) bool {
+	if strings.EqualFold(cveComponent, component) {
+		return true
+	}
+	return strings.EqualFold(cveComponent[strings.LastIndex(cveComponent, "/")+1:], component)
+}
+
+// affects check if any of the vulnerability ranges include v
+func (v *Vulnerability) affects(ver *version.Version) (bool, error) {
+	for _, a := range v.Affected {
+		for _, r := range a.Ranges {
+			// ecosystem ranges cannot be compared to a semver version
+			if r.RangeType == ecosystem {
+				continue
+			}
+			affected, err := rangeAffects(r, ver)
+			if err != nil {
+				return false, fmt.Errorf("cve #%s: %w", v.ID, err)
+			}
+			if affected {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// rangeAffects walk range events in order, an introduced event at or below ver set it affected
+// and a fixed or limit at or below ver (or a last affected below it) set it back to unaffected.
+// a range with no closing event stay affected from its introduced version onward
+func rangeAffects(r *Range, ver *version.Version) (bool, error) {
+	var affected bool
+	for _, e := range r.Events {
+		switch {
+		case len(e.Introduced) > 0:
+			if normalizeZeroVersion(e.Introduced) == "0" {
+				affected = true
+				continue
+			}
+			cmp, err := compareEvent(ver, e.Introduced)
+			if err != nil {
+				return false, err
+			}
+			if cmp >= 0 {
+				affected = true
+			}
+		case len(e.Fixed) > 0 || len(e.Limit) > 0:
+			bound := e.Fixed
+			if len(bound) == 0 {
+				bound = e.Limit
+			}
+			cmp, err := compareEvent(ver, bound)
+			if err != nil {
+				return false, err
+			}
+			if cmp >= 0 {
+				affected = false
+			}
+		case len(e.LastAffected) > 0:
+			cmp, err := compareEvent(ver, e.LastAffected)
+			if err != nil {
+				return false, err
+			}
+			if cmp > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected, nil
+}
+
+// compareEvent compare ver to an event version
+func compareEvent(ver *version.Version, event string) (int, error) {
+	ev, err := version.NewVersion(event)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event version %q: %w", event, err)
+	}
+	return ver.Compare(ev), nil
+}
@@ -0,0 +1,192 @@
+package cve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedMinorLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		events [][]*Event
+		want   []string
+	}{
+		{name: "multi range", events: [][]*Event{
+			{{Introduced: "1.22.0"}, {LastAffected: "1.22.15"}},
+			{{Introduced: "1.24.0"}, {Fixed: "1.24.7"}},
+			{{Introduced: "1.25.0"}, {Fixed: "1.25.3"}},
+		}, want: []string{"1.22", "1.24", "1.25"}},
+		{name: "range spanning lines", events: [][]*Event{
+			{{Introduced: "1.20.0"}, {Fixed: "1.23.0"}},
+		}, want: []string{"1.20", "1.21", "1.22"}},
+		{name: "introduced zero", events: [][]*Event{
+			{{Introduced: "0"}, {Fixed: "1.2.3"}},
+			{{Introduced: "1.2.0"}, {LastAffected: "1.2.0"}},
+		}, want: []string{"1.0", "1.1", "1.2"}},
+		{name: "open ended range", events: [][]*Event{
+			{{Introduced: "1.26.0"}, {Fixed: "1.26.4"}},
+			{{Introduced: "1.27.0"}},
+		}, want: []string{"1.26", "1.27", LatestMinorLine}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Vulnerability{}
+			for _, e := range tt.events {
+				v.Affected = append(v.Affected, &Affected{Ranges: []*Range{{RangeType: semver, Events: e}}})
+			}
+			assert.Equal(t, tt.want, v.AffectedMinorLines())
+		})
+	}
+}
+
+func TestAffectingVersion(t *testing.T) {
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/affected.json")}
+	tests := []struct {
+		name      string
+		component string
+		version   string
+		want      []string
+		wantErr   string
+	}{
+		{name: "introduced zero", component: "k8s.io/kubelet", version: "1.20.1", want: []string{"CVE-2023-1001"}},
+		{name: "fixed bound is not affected", component: "k8s.io/kubelet", version: "1.23.17", want: []string{}},
+		{name: "inside both ranges", component: "k8s.io/kubelet", version: "1.24.10", want: []string{"CVE-2023-1001", "CVE-2023-1002"}},
+		{name: "after last affected", component: "k8s.io/kubelet", version: "1.24.11", want: []string{"CVE-2023-1001"}},
+		{name: "outside ranges", component: "k8s.io/kubelet", version: "1.25.3", want: []string{}},
+		{name: "open ended range", component: "kubelet", version: "v1.29.0", want: []string{"CVE-2023-1003"}},
+		{name: "other component next to a malformed cve", component: "kube-proxy", version: "1.24.1", want: []string{"CVE-2023-1004"},
+			wantErr: `cve #CVE-2023-1005: invalid event version "1.24.x"`},
+		{name: "invalid version", component: "kubelet", version: "latest", wantErr: "invalid version \"latest\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.AffectingVersion(tt.component, tt.version)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			ids := make([]string, 0)
+			for _, v := range got {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestNormalizeZeroVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "0", want: "0"},
+		{version: "0.0", want: "0"},
+		{version: "0.0.0", want: "0"},
+		{version: "v0", want: "0"},
+		{version: "v0.0.0", want: "0"},
+		{version: "0.1.0", want: "0.1.0"},
+		{version: "1.0.0", want: "1.0.0"},
+		{version: "0.", want: "0."},
+		{version: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeZeroVersion(tt.version))
+		})
+	}
+}
+
+func TestGetAffectedEventsZeroIntroduced(t *testing.T) {
+	for _, introduced := range []string{"0.0", "0.0.0", "v0"} {
+		t.Run(introduced, func(t *testing.T) {
+			got := GetAffectedEvents(&Vulnerability{AffectedVersions: []*Version{{Introduced: introduced, Fixed: "1.24.2"}}})
+			assert.Equal(t, []*Affected{{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "0"}, {Fixed: "1.24.2"}}}}}}, got)
+		})
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/affected.json", "CVE-2023-1003", "CVE-2023-1004", "CVE-2023-1001")}
+	assert.Equal(t, map[string][]string{
+		"k8s.io/kubelet":    {"CVE-2023-1001", "CVE-2023-1003"},
+		"k8s.io/kube-proxy": {"CVE-2023-1004"},
+	}, db.BuildIndex())
+}
+
+func TestGetAffectedEventsLastAffectedAndFixed(t *testing.T) {
+	fixedRange := []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.3"}}}}
+	tests := []struct {
+		name    string
+		version *Version
+		want    *Affected
+	}{
+		{name: "both bounds", version: &Version{Introduced: "1.24.0", LastAffected: "1.24.2", Fixed: "1.24.3"},
+			want: &Affected{Ranges: fixedRange, DatabaseSpecific: map[string]interface{}{lastAffectedKey: "1.24.2"}}},
+		{name: "both bounds keep the version scope", version: &Version{Introduced: "1.24.0", LastAffected: "1.24.2", Fixed: "1.24.3",
+			DatabaseSpecific: map[string]interface{}{"platform": "windows"}},
+			want: &Affected{Ranges: fixedRange, DatabaseSpecific: map[string]interface{}{"platform": "windows", lastAffectedKey: "1.24.2"}}},
+		{name: "last affected not before fixed", version: &Version{Introduced: "1.24.0", LastAffected: "1.24.3", Fixed: "1.24.3"},
+			want: &Affected{Ranges: fixedRange}},
+		{name: "last affected before introduced", version: &Version{Introduced: "1.24.0", LastAffected: "1.23.9", Fixed: "1.24.3"},
+			want: &Affected{Ranges: fixedRange}},
+		{name: "fixed only", version: &Version{Introduced: "1.24.0", Fixed: "1.24.3"},
+			want: &Affected{Ranges: fixedRange}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetAffectedEvents(&Vulnerability{AffectedVersions: []*Version{tt.version}})
+			assert.Equal(t, []*Affected{tt.want}, got)
+		})
+	}
+}
+
+func TestToOSVLastAffectedAndFixed(t *testing.T) {
+	v := loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0]
+	v.AffectedVersions = []*Version{{Introduced: "1.24.0", LastAffected: "1.24.2", Fixed: "1.24.3"}}
+	v.Affected = GetAffectedEvents(v)
+	record, err := Marshal(v, FormatOSV)
+	assert.NoError(t, err)
+	got, issues := decodeRecord(record)
+	assert.Empty(t, issues)
+	assert.NotNil(t, got)
+	assert.Len(t, got.Affected[0].Ranges, 1)
+	assert.Equal(t, "1.24.2", got.Affected[0].DatabaseSpecific[lastAffectedKey])
+
+	// the exported record match versions the way an osv consumer evaluate its events
+	db := &K8sVulnDB{Cves: []*Vulnerability{got}}
+	for ver, want := range map[string]bool{"1.23.9": false, "1.24.0": true, "1.24.2": true, "1.24.3": false} {
+		matches, err := db.AffectingVersion(got.Component, ver)
+		assert.NoError(t, err)
+		assert.Equal(t, want, len(matches) == 1, ver)
+	}
+
+	// a range holding both events is reported
+	v.Affected[0].Ranges[0].Events = append(v.Affected[0].Ranges[0].Events, &Event{LastAffected: "1.24.2"})
+	record, err = Marshal(v, FormatOSV)
+	assert.NoError(t, err)
+	_, issues = decodeRecord(record)
+	assert.Equal(t, []ValidationIssue{{CveID: "CVE-2023-1001", Code: IssueSchemaViolation,
+		Message: "Affected range has both fixed and last_affected events"}}, issues)
+}
+
+func TestAddEcosystemRanges(t *testing.T) {
+	v := &Vulnerability{AffectedVersions: []*Version{{Introduced: "0", LastAffected: "1.24.2"}, {Introduced: "1.25.0", Fixed: "1.25.3"}}}
+	v.Affected = GetAffectedEvents(v)
+	addEcosystemRanges(v)
+	assert.Equal(t, []*Range{
+		{RangeType: semver, Events: []*Event{{Introduced: "0"}, {LastAffected: "1.24.2"}}},
+		{RangeType: ecosystem, Events: []*Event{{Introduced: "0"}, {LastAffected: "v1.24.2"}}},
+	}, v.Affected[0].Ranges)
+	assert.Equal(t, 2, len(v.Affected[1].Ranges))
+
+	// entries with a semver range are still ordered by it
+	unsorted := loadCves(t, "./testdata/vulndb/validation.json", "CVE-2023-1003")[0]
+	addEcosystemRanges(unsorted)
+	assert.ErrorContains(t, ValidateCveData([]*Vulnerability{unsorted}), "Affected ranges are not sorted by introduced version")
+}
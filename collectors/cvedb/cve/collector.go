@@ -1,11 +1,16 @@
 package cve
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	version "github.com/aquasecurity/go-pep440-version"
 	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
@@ -17,18 +22,103 @@ const (
 	mitreURL     = "https://cveawg.mitre.org/api/cve"
 	cveList      = "https://www.cve.org/"
 	semver       = "SEMVER"
+	// ecosystem is the range type of versions ordered by their own scheme rather than semver
+	ecosystem = "ECOSYSTEM"
 )
 
-func Collect() (*K8sVulnDB, error) {
-	response, err := http.Get(k8svulnDBURL)
-	if err != nil {
-		return nil, err
+func Collect(opts ...option) (*K8sVulnDB, error) {
+	return CollectContext(context.Background(), opts...)
+}
+
+// CollectContext collect k8s vulndb cves, cancelling outstanding fetches when ctx is done or the timeout budget
+// (see WithTimeout) is exceeded. on cancellation the cves collected so far are returned alongside the error
+func CollectContext(ctx context.Context, opts ...option) (*K8sVulnDB, error) {
+	return CollectFromFeeds(ctx, []string{k8svulnDBURL}, opts...)
+}
+
+// CollectFromFeeds collect cves from several k8s vulndb feeds (e.g. the official feed and sig specific feeds)
+// into a single vulndb, a cve listed by more than one feed is kept once as parsed from the first feed listing it
+func CollectFromFeeds(ctx context.Context, urls []string, opts ...option) (*K8sVulnDB, error) {
+	return newCollector(opts...).collectFromFeeds(ctx, urls)
+}
+
+func (c collector) collectFromFeeds(ctx context.Context, urls []string) (*K8sVulnDB, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	merged := &K8sVulnDB{Cves: make([]*Vulnerability, 0)}
+	seen := make(map[string]bool)
+	var result error
+	for _, feedURL := range urls {
+		start := time.Now()
+		vulnDB, err := c.fetch(ctx, feedURL)
+		c.stats.addPhase(phaseFeedFetch, time.Since(start))
+		if err != nil {
+			return nil, err
+		}
+		db, err := c.parseVulnDBData(ctx, vulnDB)
+		if err != nil {
+			if db == nil {
+				return nil, err
+			}
+			result = multierror.Append(result, err)
+		}
+		for _, cve := range db.Cves {
+			if seen[cve.ID] {
+				continue
+			}
+			seen[cve.ID] = true
+			merged.Cves = append(merged.Cves, cve)
+		}
+		if ctx.Err() != nil {
+			break
+		}
 	}
-	vulnDB, err := io.ReadAll(response.Body)
-	if err != nil {
+	c.stats.setEmitted(merged)
+	if err := c.checkMinCves(merged); err != nil {
+		result = multierror.Append(result, err)
+	}
+	return merged, result
+}
+
+// collectCveFromFeeds collect only the cve id from the first of the feeds listing it, a nil vulnerability is
+// returned when no feed list it or it is skipped
+func (c collector) collectCveFromFeeds(ctx context.Context, urls []string, id string) (*Vulnerability, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	for _, feedURL := range urls {
+		vulnDB, err := c.fetch(ctx, feedURL)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		items, err := c.feedParser.Parse(vulnDB)
+		if err != nil {
+			return nil, err
+		}
+		matching := make([]FeedItem, 0, 1)
+		for _, i := range items {
+			for _, cveID := range utils.GetMultiIDs(i.ID) {
+				if strings.EqualFold(strings.TrimSpace(cveID), id) {
+					matching = append(matching, i)
+					break
+				}
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		db, err := c.parseFeedItems(ctx, matching, start)
+		if db == nil {
+			return nil, err
+		}
+		for _, v := range db.Cves {
+			if strings.EqualFold(v.ID, id) {
+				return v, nil
+			}
+		}
 		return nil, err
 	}
-	return ParseVulnDBData(vulnDB)
+	return nil, nil
 }
 
 const (
@@ -36,49 +126,383 @@ const (
 	excludeNonCoreComponentsCves = "CVE-2019-11255,CVE-2020-10749,CVE-2020-8554"
 )
 
-func ParseVulnDBData(vulnDB []byte) (*K8sVulnDB, error) {
-	var db map[string]interface{}
-	err := json.Unmarshal(vulnDB, &db)
+func ParseVulnDBData(vulnDB []byte, opts ...option) (*K8sVulnDB, error) {
+	c := newCollector(opts...)
+	ctx, cancel := c.withTimeout(context.Background())
+	defer cancel()
+	db, err := c.parseVulnDBData(ctx, vulnDB)
+	c.stats.setEmitted(db)
+	if minErr := c.checkMinCves(db); minErr != nil {
+		err = multierror.Append(err, minErr)
+	}
+	return db, err
+}
+
+// checkMinCves check db has at least the WithMinCves count of cves, a nil db is not checked
+func (c collector) checkMinCves(db *K8sVulnDB) error {
+	if db == nil || len(db.Cves) >= c.minCves {
+		return nil
+	}
+	return fmt.Errorf("%w: %d collected, expected at least %d", ErrTooFewCves, len(db.Cves), c.minCves)
+}
+
+func (c collector) parseVulnDBData(ctx context.Context, vulnDB []byte) (*K8sVulnDB, error) {
+	start := time.Now()
+	items, err := c.feedParser.Parse(vulnDB)
 	if err != nil {
 		return nil, err
 	}
+	return c.parseFeedItems(ctx, items, start)
+}
+
+// parseFeedItems collect the cves of feed items parsed since start
+func (c collector) parseFeedItems(ctx context.Context, items []FeedItem, start time.Time) (*K8sVulnDB, error) {
+	c.stats.addFeedItems(len(items))
+	jobs := feedJobs(items)
+	c.stats.addPhase(phaseParse, time.Since(start))
 	fullVulnerabilities := make([]*Vulnerability, 0)
-	for _, item := range db["items"].([]interface{}) {
-		i := item.(map[string]interface{})
-		id := i["id"].(string)
+	feedTexts := make(map[string]string)
+	var strictErr error
+	c.collectCves(ctx, jobs, func(r cveResult) {
+		if r.err != nil {
+			c.stats.addSkipped(SkippedCve{CveID: jobs[r.seq].cveID, Reason: r.err.Error()})
+			strictErr = multierror.Append(strictErr, r.err)
+			return
+		}
+		if r.vulnerability == nil {
+			return
+		}
+		if c.stream != nil {
+			if !c.cvssDetails {
+				dropCvssDetails(r.vulnerability)
+			}
+			c.stream(r.vulnerability)
+		}
+		fullVulnerabilities = append(fullVulnerabilities, r.vulnerability)
+		if c.reconcile {
+			feedTexts[r.vulnerability.ID] = jobs[r.seq].item.ContentText
+		}
+	})
+	start = time.Now()
+	c.applyNVD(ctx, fullVulnerabilities)
+	c.applyOverrides(fullVulnerabilities)
+	fullVulnerabilities, enrichErr := c.enrich(fullVulnerabilities)
+	for _, cve := range fullVulnerabilities {
+		if !c.cvssDetails {
+			dropCvssDetails(cve)
+			continue
+		}
+		// tagged once nvd and overrides are applied, as both can set the cvss
+		cve.Tags = c.tagTable.Tags(cve.CvssV3.Vector)
+	}
+	if c.fixedOnly {
+		fullVulnerabilities = fixedOnly(fullVulnerabilities)
+	}
+	if c.ecosystemRanges {
+		for _, cve := range fullVulnerabilities {
+			addEcosystemRanges(cve)
+		}
+	}
+	c.annotateCollector(fullVulnerabilities)
+	c.stats.addPhase(phaseParse, time.Since(start))
+	if err := ctx.Err(); err != nil {
+		return &K8sVulnDB{validCves(fullVulnerabilities, c.severityTable)}, fmt.Errorf("k8s vulndb collection interrupted: %w", err)
+	}
+	start = time.Now()
+	issues := validationIssues(fullVulnerabilities, c.severityTable)
+	validateErr := issuesError(issues)
+	warnings := make([]ValidationIssue, 0)
+	if c.lint {
+		warnings = append(warnings, LintCveData(fullVulnerabilities)...)
+	}
+	if c.duplicateRanges {
+		warnings = append(warnings, LintDuplicateRanges(fullVulnerabilities)...)
+	}
+	if c.maxComponents > 0 {
+		warnings = append(warnings, LintComponentCount(fullVulnerabilities, c.maxComponents)...)
+	}
+	if c.releaseCheck {
+		warnings = append(warnings, CheckReleases(fullVulnerabilities, c.releases)...)
+	}
+	for _, issue := range warnings {
+		log.Printf("lint warning: %s", issue.Error())
+	}
+	issues = append(issues, warnings...)
+	if c.reconcile {
+		mismatches := reconcileFeedVersions(fullVulnerabilities, feedTexts)
+		for _, issue := range mismatches {
+			log.Printf("reconcile warning: %s", issue.Error())
+		}
+		issues = append(issues, mismatches...)
+	}
+	c.stats.addValidation(len(fullVulnerabilities), issues)
+	c.stats.addPhase(phaseValidate, time.Since(start))
+	err := multierror.Append(strictErr, enrichErr, validateErr).ErrorOrNil()
+	if err != nil {
+		if !c.partialResults {
+			return nil, err
+		}
+		return &K8sVulnDB{validCves(fullVulnerabilities, c.severityTable)}, err
+	}
+	return &K8sVulnDB{fullVulnerabilities}, nil
+}
+
+// cveJob is a single cve of a feed item to collect, seq is the cve position in the feed
+type cveJob struct {
+	seq          int
+	cveID        string
+	item         FeedItem
+	externalURLs []string
+}
+
+// feedJobs list the cves to collect from feed items, in feed order
+func feedJobs(items []FeedItem) []cveJob {
+	jobs := make([]cveJob, 0, len(items))
+	for _, i := range items {
+		id := i.ID
 		if strings.Contains(excludeNonCoreComponentsCves, id) {
 			continue
 		}
-		externalURL := i["external_url"].(string)
+		externalURL := i.ExternalURL
 		for _, cveID := range utils.GetMultiIDs(id) {
-			vulnerability, err := parseMitreCve(externalURL, cveID)
-			if err != nil || len(vulnerability.Component) == 0 {
-				continue
+			externalURLs := splitExternalURLs(externalURL)
+			if len(externalURLs) == 0 {
+				// item without advisory url, look the cve record up by id
+				externalURLs = []string{cveRecordURL(cveID)}
 			}
-			if len(vulnerability.AffectedVersions) == 0 {
-				continue
-			}
-			contentText := i["content_text"].(string)
-			component := utils.GetComponentFromDescriptionAndffected(contentText)
-
-			fullVulnerabilities = append(fullVulnerabilities, &Vulnerability{
-				ID:          cveID,
-				CreatedAt:   i["date_published"].(string),
-				Component:   getComponentName(component, vulnerability),
-				Affected:    GetAffectedEvents(vulnerability),
-				Summary:     i["summary"].(string),
-				Description: vulnerability.Description,
-				Urls:        []string{i["url"].(string), externalURL},
-				CvssV3:      vulnerability.CvssV3,
-				Severity:    vulnerability.Severity,
-			})
-		}
-	}
-	err = ValidateCveData(fullVulnerabilities)
+			jobs = append(jobs, cveJob{seq: len(jobs), cveID: cveID, item: i, externalURLs: externalURLs})
+		}
+	}
+	return jobs
+}
+
+// collectCve fetch and build a single feed cve, a nil vulnerability with nil error mean the cve is skipped
+func (c collector) collectCve(ctx context.Context, job cveJob) (*Vulnerability, error) {
+	i, cveID := job.item, job.cveID
+	if year, ok := cveYear(cveID); ok && year < c.minYear {
+		c.skip(SkippedCve{CveID: cveID, Reason: fmt.Sprintf("before min year %d", c.minYear), Filtered: true})
+		return nil, nil
+	}
+	vulnerability, err := c.firstUsableMitreCve(ctx, job.externalURLs, cveID)
 	if err != nil {
 		return nil, err
 	}
-	return &K8sVulnDB{fullVulnerabilities}, nil
+	if vulnerability == nil {
+		c.skip(SkippedCve{CveID: cveID, Reason: "no usable mitre record"})
+		return nil, nil
+	}
+	if !c.changedInWindow(vulnerability.UpdatedAt, i.DatePublished) {
+		c.skip(SkippedCve{CveID: cveID, Reason: fmt.Sprintf("not changed in the last %d days", c.changedSince), Filtered: true})
+		return nil, nil
+	}
+	start := time.Now()
+	defer func() {
+		c.stats.addPhase(phaseParse, time.Since(start))
+	}()
+	if vulnerability.Reserved && c.reserved {
+		return &Vulnerability{
+			ID:        cveID,
+			CreatedAt: i.DatePublished,
+			Summary:   i.Summary,
+			Urls:      dedupURLs(append([]string{i.URL}, job.externalURLs...)...),
+			Reserved:  true,
+		}, nil
+	}
+	contentText := i.ContentText
+	if len(vulnerability.AffectedVersions) == 0 {
+		// mitre record has no versions, degrade to the ones stated in feed content text
+		vulnerability.AffectedVersions = textAffectedVersions(contentText)
+		if len(vulnerability.AffectedVersions) == 0 {
+			c.skip(SkippedCve{CveID: cveID, Reason: "no affected versions on mitre record nor feed content text"})
+			return nil, nil
+		}
+	}
+	summary := i.Summary
+	component := c.textComponent(vulnerability.CvssV3.Vector, contentText)
+	if len(component) == 0 {
+		// feed summary often name the component when both mitre and content text detection fail
+		component = c.textComponent(vulnerability.CvssV3.Vector, summary)
+	}
+	var fallback bool
+	if len(vulnerability.Component) == 0 && len(component) == 0 {
+		if len(c.fallback) == 0 {
+			c.skip(SkippedCve{CveID: cveID, Reason: "no component detected"})
+			return nil, nil
+		}
+		log.Printf("cve %s: no component detected, fallback to %s", cveID, c.fallback)
+		component, fallback = c.fallback, true
+	}
+	componentName := getComponentName(component, vulnerability)
+	if !c.componentAllowed(componentName) {
+		c.skip(SkippedCve{CveID: cveID, Reason: fmt.Sprintf("component %s not in allowed components", componentName), Filtered: true})
+		return nil, nil
+	}
+
+	// feed url, advisory urls and mitre references frequently repeat each other
+	urls := dedupURLs(append(append([]string{i.URL}, job.externalURLs...), vulnerability.Urls...)...)
+	return &Vulnerability{
+		ID:                cveID,
+		CreatedAt:         i.DatePublished,
+		Component:         componentName,
+		Affected:          GetAffectedEvents(vulnerability),
+		Summary:           summary,
+		Description:       vulnerability.Description,
+		Urls:              urls,
+		CvssV3:            vulnerability.CvssV3,
+		CvssVersion:       vulnerability.CvssVersion,
+		Severity:          vulnerability.Severity,
+		Resources:         c.extractResources(vulnerability.Description),
+		AdvisoryRefs:      utils.ExtractAdvisoryRefs(append([]string{contentText}, urls...)...),
+		PossiblyAffected:  vulnerability.PossiblyAffected,
+		ComponentFallback: fallback,
+	}, nil
+}
+
+// cveYear return the year part of a CVE-YYYY-NNNN id
+func cveYear(cveID string) (int, bool) {
+	parts := strings.Split(cveID, "-")
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "CVE") {
+		return 0, false
+	}
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, false
+	}
+	return year, true
+}
+
+// recordDateLayouts are the mitre and feed date layouts, mitre dates sometime lack the time zone
+var recordDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// changedInWindow check if a cve updated at updatedAt, or published at published when not updated, changed
+// within the WithChangedSince window. cves with unparsable dates are kept
+func (c collector) changedInWindow(updatedAt, published string) bool {
+	if c.changedSince <= 0 {
+		return true
+	}
+	date := updatedAt
+	if len(date) == 0 {
+		date = published
+	}
+	for _, layout := range recordDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return !t.Before(c.now().AddDate(0, 0, -c.changedSince))
+		}
+	}
+	return true
+}
+
+// extractResources return the api resources named by description when resources extraction is enabled
+func (c collector) extractResources(description string) []string {
+	if !c.resources {
+		return nil
+	}
+	return utils.ExtractResources(description)
+}
+
+// fixedOnly drop cves without any fixed event, e.g. open ended or last affected only ranges
+func fixedOnly(cves []*Vulnerability) []*Vulnerability {
+	fixed := make([]*Vulnerability, 0, len(cves))
+	for _, cve := range cves {
+		if hasFixedEvent(cve) {
+			fixed = append(fixed, cve)
+		}
+	}
+	if dropped := len(cves) - len(fixed); dropped > 0 {
+		log.Printf("fixed only: dropped %d cves without a fixed version", dropped)
+	}
+	return fixed
+}
+
+func hasFixedEvent(cve *Vulnerability) bool {
+	for _, a := range cve.Affected {
+		for _, r := range a.Ranges {
+			for _, e := range r.Events {
+				if len(e.Fixed) > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// enrich run the custom enricher on each cve, cves failing enrichment are dropped and their errors aggregated
+func (c collector) enrich(cves []*Vulnerability) ([]*Vulnerability, error) {
+	if c.enricher == nil {
+		return cves, nil
+	}
+	var result error
+	enriched := make([]*Vulnerability, 0, len(cves))
+	for _, cve := range cves {
+		if err := c.enricher(cve); err != nil {
+			result = multierror.Append(result, fmt.Errorf("\nenrichment failed on cve #%s: %w", cve.ID, err))
+			continue
+		}
+		enriched = append(enriched, cve)
+	}
+	return enriched, result
+}
+
+// splitExternalURLs split feed item external url listing several advisory pages separated by spaces or commas
+func splitExternalURLs(externalURL string) []string {
+	return strings.FieldsFunc(externalURL, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// cveRecordURL return the cve.org record url of cveID
+func cveRecordURL(cveID string) string {
+	return fmt.Sprintf("%scverecord?id=%s", cveList, cveID)
+}
+
+// firstUsableMitreCve try each advisory url in turn and return the first parsed vulnerability with affected versions,
+// falling back to the first parsed one without versions so feed text can still fill them.
+// only strict mode version errors are returned, other failures just move on to the next url
+func (c collector) firstUsableMitreCve(ctx context.Context, externalURLs []string, cveID string) (*Vulnerability, error) {
+	var fallback *Vulnerability
+	for _, externalURL := range externalURLs {
+		vulnerability, err := c.parseMitreCve(ctx, externalURL, cveID, nil)
+		if errors.Is(err, ErrUnrecognizedVersion) {
+			return nil, err
+		}
+		if err != nil || vulnerability == nil {
+			continue
+		}
+		if len(vulnerability.AffectedVersions) > 0 {
+			return vulnerability, nil
+		}
+		if fallback == nil {
+			fallback = vulnerability
+		}
+	}
+	return fallback, nil
+}
+
+// skip log a skipped cve and record it on stats
+func (c collector) skip(skipped SkippedCve) {
+	log.Printf("skip cve %s: %s", skipped.CveID, skipped.Reason)
+	c.stats.addSkipped(skipped)
+}
+
+// textAffectedVersions return affected versions stated in feed content text
+func textAffectedVersions(contentText string) []*Version {
+	versions := make([]*Version, 0)
+	for _, r := range utils.ExtractTextRanges(contentText) {
+		versions = append(versions, &Version{Introduced: r.Introduced, Fixed: r.Fixed, LastAffected: r.LastAffected})
+	}
+	return versions
+}
+
+// validCves return only cves passing validation
+func validCves(cves []*Vulnerability, table utils.SeverityTable) []*Vulnerability {
+	valid := make([]*Vulnerability, 0)
+	for _, cve := range cves {
+		if validateCveData([]*Vulnerability{cve}, table) == nil {
+			valid = append(valid, cve)
+		}
+	}
+	return valid
 }
 
 func GetAffectedEvents(v *Vulnerability) []*Affected {
@@ -87,8 +511,11 @@ func GetAffectedEvents(v *Vulnerability) []*Affected {
 		if len(av.Introduced) == 0 {
 			continue
 		}
-		if av.Introduced == "0.0.0" {
-			av.Introduced = "0"
+		rangeType := semver
+		if len(av.RangeType) > 0 {
+			rangeType = av.RangeType
+		} else {
+			av.Introduced = normalizeZeroVersion(av.Introduced)
 		}
 		events := make([]*Event, 0)
 		ranges := make([]*Range, 0)
@@ -101,23 +528,147 @@ func GetAffectedEvents(v *Vulnerability) []*Affected {
 		if len(av.LastAffected) > 0 && len(av.Fixed) == 0 {
 			events = append(events, &Event{LastAffected: av.LastAffected})
 		}
-		if len(av.Introduced) > 0 && len(av.LastAffected) == 0 && len(av.Fixed) == 0 {
+		if len(av.Introduced) > 0 && len(av.LastAffected) == 0 && len(av.Fixed) == 0 && !av.OpenEnded {
 			events = append(events, &Event{LastAffected: av.Introduced})
 		}
 		ranges = append(ranges, &Range{
-			RangeType: semver,
+			RangeType: rangeType,
 			Events:    events,
 		})
-		affected = append(affected, &Affected{Ranges: ranges})
+		scope := av.DatabaseSpecific
+		if len(av.Fixed) > 0 && lastAffectedBeforeFixed(av, rangeType) {
+			// osv forbid fixed and last_affected events in the same range, and an [introduced, last_affected] range
+			// next to it would match nothing more, so the last affected version is only recorded
+			scope = make(map[string]interface{}, len(av.DatabaseSpecific)+1)
+			for k, val := range av.DatabaseSpecific {
+				scope[k] = val
+			}
+			scope[lastAffectedKey] = av.LastAffected
+		}
+		affected = append(affected, &Affected{Ranges: ranges, DatabaseSpecific: scope})
 	}
+	sortAffected(affected)
 	return affected
 }
 
+// dropCvssDetails clear the WithCvssDetails fields of v
+func dropCvssDetails(v *Vulnerability) {
+	v.CvssVersion = ""
+	v.CvssV3.Components = nil
+	v.Tags = nil
+}
+
+// lastAffectedKey is the affected database_specific key recording the last affected version of a fixed range
+const lastAffectedKey = "last_affected"
+
+// lastAffectedBeforeFixed check a version knowing both bounds can record its last affected version next to the
+// fixed one, the last affected version must be within [introduced, fixed)
+func lastAffectedBeforeFixed(av *Version, rangeType string) bool {
+	if len(av.LastAffected) == 0 || rangeType != semver {
+		return false
+	}
+	last, err := version.Parse(av.LastAffected)
+	if err != nil {
+		return false
+	}
+	fixed, err := version.Parse(av.Fixed)
+	if err != nil || !last.LessThan(fixed) {
+		return false
+	}
+	if normalizeZeroVersion(av.Introduced) == "0" {
+		return true
+	}
+	introduced, err := version.Parse(av.Introduced)
+	return err == nil && !last.LessThan(introduced)
+}
+
+// contradictoryEvents return the last affected and fixed events of a semver range whose last affected version
+// is not before its fixed version, unparsable versions are left to the version checks
+func contradictoryEvents(r *Range) (string, string, bool) {
+	if len(r.RangeType) > 0 && r.RangeType != semver {
+		return "", "", false
+	}
+	var lastAffected, fixed string
+	for _, e := range r.Events {
+		if len(e.LastAffected) > 0 {
+			lastAffected = e.LastAffected
+		}
+		if len(e.Fixed) > 0 {
+			fixed = e.Fixed
+		}
+	}
+	if len(lastAffected) == 0 || len(fixed) == 0 {
+		return "", "", false
+	}
+	last, err := version.Parse(lastAffected)
+	if err != nil {
+		return "", "", false
+	}
+	fix, err := version.Parse(fixed)
+	if err != nil || last.LessThan(fix) {
+		return "", "", false
+	}
+	return lastAffected, fixed, true
+}
+
+// httpsHosts are hosts known to serve https, their http urls are upgraded
+var httpsHosts = []string{"github.com", "kubernetes.io", "k8s.io", "cve.org", "mitre.org", "nvd.nist.gov", "groups.google.com", "hackerone.com"}
+
+// normalizeURL upgrade http urls of httpsHosts (and their subdomains) to https, urls with a scheme
+// other than http(s) are rejected
+func normalizeURL(rawURL string) (string, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Host) == 0 {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return rawURL, true
+	case "http":
+		host := strings.ToLower(u.Hostname())
+		for _, h := range httpsHosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return "https" + rawURL[len(u.Scheme):], true
+			}
+		}
+		return rawURL, true
+	}
+	return "", false
+}
+
+// dedupURLs return normalized urls with duplicates and rejected urls removed, keeping each url first position
+func dedupURLs(urls ...string) []string {
+	seen := make(map[string]bool, len(urls))
+	deduped := make([]string, 0, len(urls))
+	for _, u := range urls {
+		u, ok := normalizeURL(u)
+		if !ok || seen[u] {
+			continue
+		}
+		seen[u] = true
+		deduped = append(deduped, u)
+	}
+	return deduped
+}
+
+// textComponent return the component detected in description, by keyword weight with WithWeightedComponents
+func (c collector) textComponent(vector, description string) string {
+	if c.weighted {
+		return utils.GetComponentByKeywordWeight(vector, description)
+	}
+	return utils.GetComponentFromDescriptionAndCvss(vector, description)
+}
+
 func getComponentName(k8sComponent string, mitreCve *Vulnerability) string {
 	// prefer mitre component if exists
 	if len(mitreCve.Component) != 0 && strings.ToLower(mitreCve.Component) != "kubernetes" {
 		k8sComponent = mitreCve.Component
 	}
+	// product already qualified on mitre, resolving it again would prefix a second org
+	if componentPathRegex.MatchString(k8sComponent) {
+		return strings.ToLower(k8sComponent)
+	}
 	upstreamPrefix := utils.UpstreamOrgByName(k8sComponent)
 	if upstreamPrefix != "" {
 		return strings.ToLower(fmt.Sprintf("%s/%s", upstreamPrefix, utils.UpstreamRepoByName(k8sComponent)))
@@ -126,47 +677,163 @@ func getComponentName(k8sComponent string, mitreCve *Vulnerability) string {
 	return strings.ToLower(fmt.Sprintf("%s/%s", av, utils.UpstreamRepoByName(mitreCve.Component)))
 }
 
+// ValidateCveData check collected cves are complete and consistent, severity labels are expected to follow
+// the standard cvss bands
 func ValidateCveData(cves []*Vulnerability) error {
+	return validateCveData(cves, utils.DefaultSeverityTable)
+}
+
+// componentPathRegex match the org/repo path built by getComponentName
+var componentPathRegex = regexp.MustCompile(`^[^/]+/[^/]+$`)
+
+// validateCveData is ValidateCveData with severity labels checked against table
+func validateCveData(cves []*Vulnerability, table utils.SeverityTable) error {
+	return issuesError(validationIssues(cves, table))
+}
+
+// issuesError return the validation errors of issues, warnings excluded
+func issuesError(issues []ValidationIssue) error {
 	var result error
+	for _, issue := range issues {
+		if !issue.Warning {
+			result = multierror.Append(result, fmt.Errorf("\n%w", issue))
+		}
+	}
+	return result
+}
+
+// validationIssues return the validation issues of cves, severity labels are checked against table
+func validationIssues(cves []*Vulnerability, table utils.SeverityTable) []ValidationIssue {
+	var issues issueList
+	seenIDs := make(map[string]int)
+	cache := newValidationCache()
+	add := issues.add
 	for _, cve := range cves {
+		issues.cveID = cve.ID
+		seenIDs[cve.ID]++
+		if seenIDs[cve.ID] == 2 && len(cve.ID) > 0 {
+			add(IssueDuplicateID, "id is duplicated")
+		}
 		if len(cve.ID) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nid is mssing on cve #%s", cve.ID))
+			add(IssueMissingID, "id is mssing")
+		}
+		if cve.Reserved {
+			// reserved placeholders carry no details yet
+			continue
 		}
 		if len(cve.CreatedAt) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nCreatedAt is mssing on cve #%s", cve.ID))
+			add(IssueMissingCreatedAt, "CreatedAt is mssing")
 		}
 		if len(cve.Summary) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nSummary is mssing on cve #%s", cve.ID))
+			add(IssueMissingSummary, "Summary is mssing")
 		}
-		if len(strings.TrimPrefix(cve.Component, utils.UpstreamOrgByName(cve.Component))) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nComponent is mssing on cve #%s", cve.ID))
+		if cve.Component == cache.upstreamOrg(cve.Component) {
+			add(IssueMissingComponent, "Component is mssing")
+		} else if !componentPathRegex.MatchString(cve.Component) {
+			add(IssueInvalidComponent, "Component %s is not an org/repo path", cve.Component)
 		}
 		if len(cve.Description) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nDescription is mssing on cve #%s", cve.ID))
+			add(IssueMissingDescription, "Description is mssing")
 		}
 		if len(cve.Affected) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nFixedVersion is missing on cve #%s", cve.ID))
+			add(IssueMissingFixedVersion, "FixedVersion is missing")
+		}
+		for i, a := range cve.Affected {
+			if i > 0 && cache.affectedLess(a, cve.Affected[i-1]) {
+				add(IssueUnsortedRanges, "Affected ranges are not sorted by introduced version")
+			}
+			for _, r := range a.Ranges {
+				if emptyEvents(r) {
+					add(IssueEmptyRange, "Affected range has no events")
+				}
+				if last, fixed, ok := contradictoryEvents(r); ok {
+					add(IssueContradictoryRange, "Affected range last affected %s is not before fixed %s", last, fixed)
+				}
+			}
 		}
 		if len(cve.Affected) > 0 {
 			for _, v := range cve.AffectedVersions {
-				_, err := version.Parse(v.Introduced)
-				if err != nil {
-					result = multierror.Append(result, fmt.Errorf("\nAffectedVersion From %s is invalid on cve #%s", v.Introduced, cve.ID))
+				// non semver ranges keep their raw bounds
+				if len(v.RangeType) == 0 && !cache.validVersion(v.Introduced) {
+					add(IssueInvalidVersion, "AffectedVersion From %s is invalid", v.Introduced)
 				}
 			}
 		}
 		if cve.CvssV3.Score == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nVector is mssing on cve #%s", cve.ID))
+			add(IssueMissingScore, "Vector is mssing")
 		}
 		if cve.CvssV3.Vector == "" {
-			result = multierror.Append(result, fmt.Errorf("\nVector is mssing on cve #%s", cve.ID))
+			add(IssueMissingVector, "Vector is mssing")
 		}
 		if cve.Severity == "" {
-			result = multierror.Append(result, fmt.Errorf("\nSeverity is mssing on cve #%s", cve.ID))
+			add(IssueMissingSeverity, "Severity is mssing")
+		}
+		// a curated severity override may deliberately disagree with the score band
+		if cve.Severity != "" && cve.CvssV3.Score != 0 && !overridden(cve, "severity") && !strings.EqualFold(cve.Severity, table.Severity(cve.CvssV3.Score)) {
+			add(IssueSeverityMismatch, "Severity %s does not match score %.1f", cve.Severity, cve.CvssV3.Score)
 		}
 		if len(cve.Urls) == 0 {
-			result = multierror.Append(result, fmt.Errorf("\nUrls is mssing on cve #%s", cve.ID))
+			add(IssueMissingUrls, "Urls is mssing")
 		}
 	}
-	return result
+	return issues.issues
+}
+
+// issueList collect the issues of the cve being validated
+type issueList struct {
+	issues []ValidationIssue
+	cveID  string
+}
+
+func (l *issueList) add(code, format string, args ...interface{}) {
+	l.issues = append(l.issues, ValidationIssue{CveID: l.cveID, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// validationCache memoize lookups repeated across a database validation,
+// components and versions are shared by most cves so each is parsed once
+type validationCache struct {
+	orgs     map[string]string
+	versions map[string]bool
+	less     map[[2]string]bool
+}
+
+func newValidationCache() *validationCache {
+	return &validationCache{
+		orgs:     make(map[string]string),
+		versions: make(map[string]bool),
+		less:     make(map[[2]string]bool),
+	}
+}
+
+// upstreamOrg return cached upstream org of component
+func (vc *validationCache) upstreamOrg(component string) string {
+	org, ok := vc.orgs[component]
+	if !ok {
+		org = utils.UpstreamOrgByName(component)
+		vc.orgs[component] = org
+	}
+	return org
+}
+
+// validVersion check if v is a valid version, caching the parse result
+func (vc *validationCache) validVersion(v string) bool {
+	valid, ok := vc.versions[v]
+	if !ok {
+		_, err := version.Parse(v)
+		valid = err == nil
+		vc.versions[v] = valid
+	}
+	return valid
+}
+
+// affectedLess is affectedLess with the result cached per introduced versions pair,
+// version compare allocate on every call
+func (vc *validationCache) affectedLess(a, b *Affected) bool {
+	key := [2]string{introducedVersion(a), introducedVersion(b)}
+	less, ok := vc.less[key]
+	if !ok {
+		less = affectedLess(a, b)
+		vc.less[key] = less
+	}
+	return less
 }
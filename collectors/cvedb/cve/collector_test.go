@@ -1,25 +1,682 @@
 package cve
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
 	"github.com/stretchr/testify/assert"
 )
 
+// update rewrite golden files with the test output, e.g. go test ./collectors/cvedb/cve -run Test_ParseVulneDB -update
+var update = flag.Bool("update", false, "update golden files")
+
 func Test_ParseVulneDB(t *testing.T) {
+	// mitre records of the feed cves are served from testdata/mitre, feed cves without one are skipped
+	ts := newMitreServer(t)
 	b, err := os.ReadFile("./testdata/k8s-db.json")
 	assert.NoError(t, err)
-	kvd, err := ParseVulnDBData(b)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
 	assert.NoError(t, err)
 	err = ValidateCveData(kvd.Cves)
 	assert.NoError(t, err)
 	gotVulnDB, err := json.Marshal(kvd.Cves)
 	assert.NoError(t, err)
-	err = os.WriteFile("./testdata/expected-vulndb.json", gotVulnDB, 0644)
-	assert.NoError(t, err)
+	if *update {
+		err = os.WriteFile("./testdata/expected-vulndb.json", gotVulnDB, 0644)
+		assert.NoError(t, err)
+	}
 	wantVulnDB, err := os.ReadFile("./testdata/expected-vulndb.json")
 	assert.NoError(t, err)
 	assert.Equal(t, string(wantVulnDB), string(gotVulnDB))
 }
+
+// serveMitreRecord serve mitre cve records from testdata/mitre by cve id
+func serveMitreRecord(w http.ResponseWriter, r *http.Request) {
+	b, err := os.ReadFile(filepath.Join("./testdata/mitre", path.Base(r.URL.Path)+".json"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(b)
+}
+
+func newMitreServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(serveMitreRecord))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestParseVulnDBDataPartialResults(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/partial.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		opts    []option
+		wantIDs []string
+	}{
+		{name: "fail on validation errors", opts: []option{WithMitreURL(ts.URL)}},
+		{name: "return partial db", opts: []option{WithMitreURL(ts.URL), WithPartialResults()}, wantIDs: []string{"CVE-2023-1001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.ErrorContains(t, err, "Vector is mssing on cve #CVE-2023-1002")
+			if tt.wantIDs == nil {
+				assert.Nil(t, kvd)
+				return
+			}
+			gotIDs := make([]string, 0)
+			for _, v := range kvd.Cves {
+				gotIDs = append(gotIDs, v.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+// loadCves return the cves of a collector output fixture, only the ones of ids and in their order when given
+func loadCves(t testing.TB, dataPath string, ids ...string) []*Vulnerability {
+	b, err := os.ReadFile(dataPath)
+	assert.NoError(t, err)
+	var cves []*Vulnerability
+	assert.NoError(t, json.Unmarshal(b, &cves))
+	if len(ids) == 0 {
+		return cves
+	}
+	byID := make(map[string]*Vulnerability)
+	for _, v := range cves {
+		byID[v.ID] = v
+	}
+	selected := make([]*Vulnerability, 0, len(ids))
+	for _, id := range ids {
+		v, ok := byID[id]
+		if !ok {
+			t.Fatalf("cve %s is not in %s", id, dataPath)
+		}
+		selected = append(selected, v)
+	}
+	return selected
+}
+
+func TestValidateCveData(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []string
+		wantErr string
+	}{
+		{name: "valid cves", ids: []string{"CVE-2023-1001", "CVE-2023-1002"}},
+		{name: "duplicated id", ids: []string{"CVE-2023-1001", "CVE-2023-1002", "CVE-2023-1001"},
+			wantErr: "id is duplicated on cve #CVE-2023-1001"},
+		{name: "unsorted ranges", ids: []string{"CVE-2023-1003"},
+			wantErr: "Affected ranges are not sorted by introduced version on cve #CVE-2023-1003"},
+		{name: "sorted ranges", ids: []string{"CVE-2023-1004"}},
+		{name: "empty events", ids: []string{"CVE-2023-1005"},
+			wantErr: "Affected range has no events on cve #CVE-2023-1005"},
+		{name: "contradictory range", ids: []string{"CVE-2023-1006"},
+			wantErr: "Affected range last affected 1.24.14 is not before fixed 1.24.14 on cve #CVE-2023-1006"},
+		{name: "consistent range", ids: []string{"CVE-2023-1007"}},
+		{name: "consistent severity", ids: []string{"CVE-2023-1008"}},
+		{name: "inconsistent severity", ids: []string{"CVE-2023-1009"},
+			wantErr: "Severity Critical does not match score 4.0 on cve #CVE-2023-1009"},
+		{name: "org/repo component", ids: []string{"CVE-2023-1010"}},
+		{name: "component without repo", ids: []string{"CVE-2023-1011"},
+			wantErr: "Component k8s.io/ is not an org/repo path on cve #CVE-2023-1011"},
+		{name: "nested component path", ids: []string{"CVE-2023-1012"},
+			wantErr: "Component a/b/c is not an org/repo path on cve #CVE-2023-1012"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCveData(loadCves(t, "./testdata/vulndb/validation.json", tt.ids...))
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseVulnDBDataComponentFromSummary(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/summary-component.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "k8s.io/apiserver", kvd.Cves[0].Component)
+}
+
+func TestParseVulnDBDataReferences(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/references.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, []string{
+		"https://github.com/kubernetes/kubernetes/issues/1004",
+		"https://www.cve.org/cverecord?id=CVE-2023-1004",
+		"https://groups.google.com/g/kubernetes-security-announce/c/abc1004",
+		"https://github.com/kubernetes/kubernetes/pull/1005",
+	}, kvd.Cves[0].Urls)
+}
+
+func TestParseVulnDBDataMitreError(t *testing.T) {
+	externalURL := "https://example.com/advisories/CVE-2023-1001"
+	vulnerability, err := newCollector().parseMitreCve(context.Background(), externalURL, "CVE-2023-1001", nil)
+	assert.Error(t, err)
+	assert.Nil(t, vulnerability)
+
+	b, err := os.ReadFile("./testdata/feed/unsupported-url.json")
+	assert.NoError(t, err)
+	assert.NotPanics(t, func() {
+		kvd, err := ParseVulnDBData(b)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(kvd.Cves))
+	})
+}
+
+func TestParseVulnDBDataTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "CVE-2023-1004" {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+		serveMitreRecord(w, r)
+	}))
+	defer ts.Close()
+	b, err := os.ReadFile("./testdata/feed/timeout.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithTimeout(500*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+}
+
+func TestParseVulnDBDataNotApplicableProduct(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/not-applicable.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "k8s.io/kube-scheduler", kvd.Cves[0].Component)
+}
+
+func TestParseVulnDBDataQualifiedProduct(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/qualified-product.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "kubernetes/ingress-nginx", kvd.Cves[0].Component)
+}
+
+func TestParseVulnDBDataCollectionURL(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/collection-url.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "kubernetes/ingress-nginx", kvd.Cves[0].Component)
+}
+
+func TestParseVulnDBDataWeightedComponents(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/weighted-component.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts []option
+		want string
+	}{
+		{name: "most mentioned component", opts: []option{WithMitreURL(ts.URL)}, want: "k8s.io/kubelet"},
+		{name: "primary component", opts: []option{WithMitreURL(ts.URL), WithWeightedComponents()}, want: "k8s.io/kube-proxy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(kvd.Cves))
+			assert.Equal(t, tt.want, kvd.Cves[0].Component)
+		})
+	}
+}
+
+func TestParseVulnDBDataEcosystemRanges(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/collection-url.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithEcosystemRanges())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, []*Range{
+		{RangeType: semver, Events: []*Event{{Introduced: "1.9.0"}, {Fixed: "1.9.4"}}},
+		{RangeType: ecosystem, Events: []*Event{{Introduced: "v1.9.0"}, {Fixed: "v1.9.4"}}},
+	}, kvd.Cves[0].ToOSV().Affected[0].Ranges)
+}
+
+func TestParseVulnDBDataFallbackComponent(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/no-component.json")
+	assert.NoError(t, err)
+
+	stats := &CollectStats{}
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithStats(stats))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(kvd.Cves))
+	assert.Equal(t, []SkippedCve{{CveID: "CVE-2023-1041", Reason: "no component detected"}}, stats.SkippedCves)
+
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithFallbackComponent("kubernetes/kubernetes"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "kubernetes/kubernetes", kvd.Cves[0].Component)
+	assert.True(t, kvd.Cves[0].ComponentFallback)
+}
+
+func TestParseVulnDBDataAllowComponents(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		opts    []option
+		wantIDs []string
+	}{
+		{name: "all components", opts: []option{WithMitreURL(ts.URL)}, wantIDs: []string{"CVE-2023-1001", "CVE-2023-1003", "CVE-2023-1004"}},
+		{name: "restrict to apiserver", opts: []option{WithMitreURL(ts.URL), WithAllowComponents("k8s.io/apiserver")}, wantIDs: []string{"CVE-2023-1003"}},
+		{name: "restrict to node components", opts: []option{WithMitreURL(ts.URL), WithAllowComponents("k8s.io/kubelet", "K8s.io/Kube-Proxy")}, wantIDs: []string{"CVE-2023-1001", "CVE-2023-1004"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.NoError(t, err)
+			gotIDs := make([]string, 0)
+			for _, v := range kvd.Cves {
+				gotIDs = append(gotIDs, v.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestParseVulnDBDataMinCves(t *testing.T) {
+	ts := newMitreServer(t)
+	empty, err := os.ReadFile("./testdata/feed/empty.json")
+	assert.NoError(t, err)
+	components, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		feed    []byte
+		opts    []option
+		wantErr bool
+	}{
+		{name: "empty feed without guard", feed: empty},
+		{name: "empty feed", feed: empty, opts: []option{WithMinCves(1)}, wantErr: true},
+		{name: "below min count", feed: components, opts: []option{WithMinCves(4)}, wantErr: true},
+		{name: "at min count", feed: components, opts: []option{WithMinCves(3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(tt.feed, append(tt.opts, WithMitreURL(ts.URL))...)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrTooFewCves)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, ValidateCveData(kvd.Cves))
+		})
+	}
+}
+
+func TestCollectFromFeeds(t *testing.T) {
+	mitre := newMitreServer(t)
+	feeds := http.NewServeMux()
+	feeds.HandleFunc("/control-plane.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/feed/single.json")
+	})
+	feeds.HandleFunc("/ecosystem.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/feed/components.json")
+	})
+	ts := httptest.NewServer(feeds)
+	defer ts.Close()
+
+	kvd, err := CollectFromFeeds(context.Background(), []string{ts.URL + "/control-plane.json", ts.URL + "/ecosystem.json"}, WithMitreURL(mitre.URL))
+	assert.NoError(t, err)
+	gotIDs := make([]string, 0)
+	for _, v := range kvd.Cves {
+		gotIDs = append(gotIDs, v.ID)
+	}
+	assert.Equal(t, []string{"CVE-2023-1001", "CVE-2023-1003", "CVE-2023-1004"}, gotIDs)
+	assert.NoError(t, ValidateCveData(kvd.Cves))
+
+	_, err = CollectFromFeeds(context.Background(), []string{ts.URL + "/missing.json"}, WithMitreURL(mitre.URL))
+	assert.ErrorIs(t, err, ErrUpstream)
+}
+
+func TestParseVulnDBDataEnricher(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	enricher := func(v *Vulnerability) error {
+		if v.ID == "CVE-2023-1004" {
+			return fmt.Errorf("ticket lookup failed")
+		}
+		v.DatabaseSpecific = map[string]interface{}{"ticket": "SEC-" + v.ID}
+		return nil
+	}
+
+	_, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithEnricher(enricher))
+	assert.ErrorContains(t, err, "enrichment failed on cve #CVE-2023-1004: ticket lookup failed")
+
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithEnricher(enricher), WithPartialResults())
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	for _, v := range kvd.Cves {
+		assert.Equal(t, "SEC-"+v.ID, v.DatabaseSpecific["ticket"])
+	}
+}
+
+func TestParseVulnDBDataMultipleExternalURLs(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/multiple-urls.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "k8s.io/kubelet", kvd.Cves[0].Component)
+	assert.Equal(t, []string{
+		"https://github.com/kubernetes/kubernetes/issues/1001",
+		"https://github.com/kubernetes/kubernetes/security/advisories/GHSA-1001",
+		"https://www.cve.org/cverecord?id=CVE-2023-1001",
+	}, kvd.Cves[0].Urls)
+}
+
+func TestParseVulnDBDataTextVersions(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/text-versions.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, []*Affected{
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.23.0"}, {Fixed: "1.23.8"}}}}},
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.2"}}}}},
+	}, kvd.Cves[0].Affected)
+}
+
+// benchmarkCves build a database of n valid cves spanning a few components with several affected lines each,
+// copies of the collected CVE-2023-2431
+func benchmarkCves(b *testing.B, n int) []*Vulnerability {
+	collected := loadCves(b, "./testdata/expected-vulndb.json", "CVE-2023-2431")[0]
+	components := []string{"k8s.io/kubelet", "k8s.io/apiserver", "k8s.io/kube-proxy", "k8s.io/kube-controller-manager"}
+	cves := make([]*Vulnerability, 0, n)
+	for i := 0; i < n; i++ {
+		v := *collected
+		v.ID = fmt.Sprintf("CVE-2023-%d", 10000+i)
+		v.Component = components[i%len(components)]
+		v.AffectedVersions = []*Version{{Introduced: "1.24.0"}, {Introduced: "1.25.0"}, {Introduced: "1.26.0"}, {Introduced: "1.27.0"}}
+		cves = append(cves, &v)
+	}
+	return cves
+}
+
+// BenchmarkValidateCveData on 5000 cves, before and after caching org lookups and version parsing:
+//
+//	before: 110ms/op  43.6MB/op  760064 allocs/op
+//	after:  2.5ms/op  0.45MB/op     264 allocs/op
+func BenchmarkValidateCveData(b *testing.B) {
+	cves := benchmarkCves(b, 5000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ValidateCveData(cves); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseVulnDBDataSeverityTable(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	redHat := utils.SeverityTable{{MinScore: 0.1, Label: "Low"}, {MinScore: 4.0, Label: "Moderate"}, {MinScore: 7.0, Label: "Important"}, {MinScore: 9.0, Label: "Critical"}}
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithSeverityTable(redHat))
+	assert.NoError(t, err)
+	severities := make(map[string]string)
+	for _, v := range kvd.Cves {
+		severities[v.ID] = v.Severity
+	}
+	assert.Equal(t, "Low", severities["CVE-2023-1001"])
+	assert.Equal(t, "Important", severities["CVE-2023-1003"])
+}
+
+func TestParseVulnDBDataExploitabilityTags(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	tests := []struct {
+		name string
+		opts []option
+		want map[string][]string
+	}{
+		{name: "default table", opts: []option{WithMitreURL(ts.URL), WithCvssDetails(true)}, want: map[string][]string{
+			"CVE-2023-1001": {"local-exploitable", "low-complexity", "no-user-interaction"},
+			"CVE-2023-1003": {"network-exploitable", "low-complexity", "no-user-interaction"},
+		}},
+		{name: "custom table", opts: []option{WithMitreURL(ts.URL), WithCvssDetails(true), WithExploitabilityTags(utils.ExploitabilityTagTable{"AV:N": "remote"})},
+			want: map[string][]string{"CVE-2023-1001": nil, "CVE-2023-1003": {"remote"}}},
+		{name: "without cvss details", opts: []option{WithMitreURL(ts.URL)},
+			want: map[string][]string{"CVE-2023-1001": nil, "CVE-2023-1003": nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.NoError(t, err)
+			tags := make(map[string][]string)
+			for _, v := range kvd.Cves {
+				if _, ok := tt.want[v.ID]; ok {
+					tags[v.ID] = v.Tags
+				}
+			}
+			assert.Equal(t, tt.want, tags)
+		})
+	}
+}
+
+func TestParseVulnDBDataFixedOnly(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/fixed-only.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithFixedOnly())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+}
+
+func TestParseVulnDBDataDedupURLs(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/same-urls.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, []string{
+		"https://www.cve.org/cverecord?id=CVE-2023-1004",
+		"https://github.com/kubernetes/kubernetes/issues/1004",
+		"https://groups.google.com/g/kubernetes-security-announce/c/abc1004",
+		"https://github.com/kubernetes/kubernetes/pull/1005",
+	}, kvd.Cves[0].Urls)
+}
+
+func TestParseVulnDBDataResources(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/resources.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.Nil(t, kvd.Cves[1].Resources)
+
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithResources())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.Nil(t, kvd.Cves[0].Resources)
+	assert.Equal(t, []string{"discovery.k8s.io/v1/EndpointSlice", "v1/Service"}, kvd.Cves[1].Resources)
+}
+
+func TestParseVulnDBDataMissingExternalURL(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/missing-external-url.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+	assert.Equal(t, []string{
+		"https://github.com/kubernetes/kubernetes/issues/1001",
+		"https://www.cve.org/cverecord?id=CVE-2023-1001",
+	}, kvd.Cves[0].Urls)
+	assert.Equal(t, "CVE-2023-1004", kvd.Cves[1].ID)
+}
+
+func TestParseVulnDBDataReserved(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/reserved.json")
+	assert.NoError(t, err)
+
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithReserved())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.False(t, kvd.Cves[0].Reserved)
+	assert.Equal(t, &Vulnerability{
+		ID:        "CVE-2023-1019",
+		CreatedAt: "2023-08-01T10:00:00Z",
+		Summary:   "Reserved kubelet issue",
+		Urls:      []string{"https://github.com/kubernetes/kubernetes/issues/1019", "https://www.cve.org/cverecord?id=CVE-2023-1019"},
+		Reserved:  true,
+	}, kvd.Cves[1])
+}
+
+func TestParseVulnDBDataMinYear(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/min-year.json")
+	assert.NoError(t, err)
+	tests := []struct {
+		name    string
+		opts    []option
+		wantIDs []string
+	}{
+		{name: "no min year", opts: []option{WithMitreURL(ts.URL)}, wantIDs: []string{"CVE-2015-1001", "CVE-2023-1001"}},
+		{name: "skip older cves", opts: []option{WithMitreURL(ts.URL), WithMinYear(2016)}, wantIDs: []string{"CVE-2023-1001"}},
+		{name: "min year is inclusive", opts: []option{WithMitreURL(ts.URL), WithMinYear(2015)}, wantIDs: []string{"CVE-2015-1001", "CVE-2023-1001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.NoError(t, err)
+			var gotIDs []string
+			for _, v := range kvd.Cves {
+				gotIDs = append(gotIDs, v.ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+		})
+	}
+}
+
+func TestParseVulnDBDataChangedSince(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/changed-since.json")
+	assert.NoError(t, err)
+	now := func() time.Time { return time.Date(2023, 7, 10, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		opts []option
+		want []string
+	}{
+		{name: "all cves", opts: []option{WithMitreURL(ts.URL)}, want: []string{"CVE-2023-1001", "CVE-2023-1031", "CVE-2023-1032"}},
+		// CVE-2023-1001 has no dateUpdated and is kept on its date_published
+		{name: "last 30 days", opts: []option{WithMitreURL(ts.URL), WithChangedSince(30), WithClock(now)}, want: []string{"CVE-2023-1001", "CVE-2023-1031"}},
+		{name: "last 7 days", opts: []option{WithMitreURL(ts.URL), WithChangedSince(7), WithClock(now)}, want: []string{"CVE-2023-1031"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kvd, err := ParseVulnDBData(b, tt.opts...)
+			assert.NoError(t, err)
+			var ids []string
+			for _, v := range kvd.Cves {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestParseVulnDBDataAdvisoryRefs(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/advisory-refs.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.Equal(t, []string{
+		"https://github.com/kubernetes/enhancements/tree/master/keps/sig-node/2413-seccomp-by-default",
+		"https://github.com/kubernetes/kubernetes/issues/1001",
+		"https://github.com/kubernetes/kubernetes/issues/118690",
+	}, kvd.Cves[0].AdvisoryRefs)
+	assert.Nil(t, kvd.Cves[1].AdvisoryRefs)
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		want   string
+		wantOk bool
+	}{
+		{name: "https kept", url: "https://github.com/kubernetes/kubernetes/issues/1001", want: "https://github.com/kubernetes/kubernetes/issues/1001", wantOk: true},
+		{name: "http upgraded", url: "http://github.com/kubernetes/kubernetes/issues/1001", want: "https://github.com/kubernetes/kubernetes/issues/1001", wantOk: true},
+		{name: "http subdomain upgraded", url: "HTTP://www.kubernetes.io/docs ", want: "https://www.kubernetes.io/docs", wantOk: true},
+		{name: "http unknown host kept", url: "http://example.com/advisory", want: "http://example.com/advisory", wantOk: true},
+		{name: "ftp dropped", url: "ftp://ftp.example.com/advisory.txt"},
+		{name: "no scheme dropped", url: "github.com/kubernetes/kubernetes/issues/1001"},
+		{name: "empty dropped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := normalizeURL(tt.url)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, []string{"https://github.com/kubernetes/kubernetes/issues/1001"},
+		dedupURLs("http://github.com/kubernetes/kubernetes/issues/1001", "ftp://ftp.example.com/advisory.txt", "https://github.com/kubernetes/kubernetes/issues/1001"))
+}
@@ -0,0 +1,196 @@
+package cve
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// WithConcurrency set how many cves are collected in parallel, results keep the feed order whatever the
+// completion order. default to 1
+func WithConcurrency(n int) option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithAdaptiveConcurrency adapt the number of cves collected in parallel to upstream throttling, the
+// WithConcurrency workers are halved on each 429 response and grown back one at a time as fetches succeed
+func WithAdaptiveConcurrency() option {
+	return func(o *options) {
+		o.adaptiveConcurrency = true
+	}
+}
+
+// WithStream set a callback invoked with each collected cve, before enrichment and validation, in feed order
+// as soon as the cve and every cve listed before it are collected
+func WithStream(stream func(*Vulnerability)) option {
+	return func(o *options) {
+		o.stream = stream
+	}
+}
+
+// cveResult is the outcome of a cveJob
+type cveResult struct {
+	seq           int
+	vulnerability *Vulnerability
+	err           error
+}
+
+// collectCves collect jobs on the configured number of workers and hand results to emit in jobs order,
+// results completing ahead of an earlier job wait in a reorder buffer until that job is emitted.
+// no job is started once ctx is done
+func (c collector) collectCves(ctx context.Context, jobs []cveJob, emit func(cveResult)) {
+	if c.concurrency <= 1 {
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return
+			}
+			v, err := c.collectCve(ctx, job)
+			emit(cveResult{seq: job.seq, vulnerability: v, err: err})
+		}
+		return
+	}
+	pending := make(chan cveJob)
+	results := make(chan cveResult)
+	var wg sync.WaitGroup
+	for w := 0; w < c.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range pending {
+				if !c.limiter.acquire(ctx) {
+					results <- cveResult{seq: job.seq}
+					continue
+				}
+				v, err := c.collectCve(ctx, job)
+				c.limiter.release()
+				results <- cveResult{seq: job.seq, vulnerability: v, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- job:
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	buffer := make(map[int]cveResult)
+	next := 0
+	for r := range results {
+		buffer[r.seq] = r
+		for {
+			ready, ok := buffer[next]
+			if !ok {
+				break
+			}
+			delete(buffer, next)
+			emit(ready)
+			next++
+		}
+	}
+}
+
+// aimdLimiter bound how many cves are collected at once with additive increase / multiplicative decrease:
+// each throttled fetch halve the limit and as many successful fetches as the limit grow it back by one
+type aimdLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	max       int
+	active    int
+	successes int
+	// lowest is the lowest limit reached
+	lowest int
+	// wake is closed and replaced whenever a slot may have freed up
+	wake chan struct{}
+}
+
+func newAIMDLimiter(max int) *aimdLimiter {
+	return &aimdLimiter{limit: max, max: max, lowest: max, wake: make(chan struct{})}
+}
+
+// acquire wait for a free slot, false when ctx is done first. a nil limiter never wait
+func (l *aimdLimiter) acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return true
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-wake:
+		}
+	}
+}
+
+// release free a slot taken by acquire
+func (l *aimdLimiter) release() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.broadcast()
+}
+
+// throttled halve the limit, down to a single worker
+func (l *aimdLimiter) throttled() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.successes = 0
+	if l.limit == 1 {
+		return
+	}
+	l.limit /= 2
+	if l.limit < l.lowest {
+		l.lowest = l.limit
+	}
+	log.Printf("upstream is throttling, reduce concurrency to %d", l.limit)
+}
+
+// succeeded grow the limit by one, up to max, after as many successes as the current limit
+func (l *aimdLimiter) succeeded() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit == l.max {
+		return
+	}
+	l.successes++
+	if l.successes >= l.limit {
+		l.limit++
+		l.successes = 0
+		l.broadcast()
+	}
+}
+
+// broadcast wake the goroutines waiting in acquire, mu must be held
+func (l *aimdLimiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
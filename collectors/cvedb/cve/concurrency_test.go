@@ -0,0 +1,94 @@
+package cve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVulnDBDataConcurrency(t *testing.T) {
+	// earlier feed cves answer last so workers complete in reverse feed order
+	delays := map[string]time.Duration{"CVE-2023-1001": 80 * time.Millisecond, "CVE-2023-1003": 40 * time.Millisecond}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delays[path.Base(r.URL.Path)])
+		serveMitreRecord(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	want := []string{"CVE-2023-1001", "CVE-2023-1003", "CVE-2023-1004"}
+
+	for _, n := range []int{1, 3} {
+		var streamed []string
+		kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithConcurrency(n), WithStream(func(v *Vulnerability) {
+			streamed = append(streamed, v.ID)
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, want, streamed)
+		var ids []string
+		for _, v := range kvd.Cves {
+			ids = append(ids, v.ID)
+		}
+		assert.Equal(t, want, ids)
+	}
+}
+
+func TestParseVulnDBDataAdaptiveConcurrency(t *testing.T) {
+	// the first requests are throttled, as a mitre api rate limit burst would
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		serveMitreRecord(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+
+	c := newCollector(WithMitreURL(ts.URL), WithConcurrency(8), WithAdaptiveConcurrency(),
+		WithRetries(3), WithRetryBackoff(time.Millisecond, time.Millisecond))
+	kvd, err := c.parseVulnDBData(context.Background(), b)
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 3)
+	assert.Equal(t, 1, c.limiter.lowest)
+}
+
+func TestAIMDLimiter(t *testing.T) {
+	l := newAIMDLimiter(8)
+	l.throttled()
+	l.throttled()
+	assert.Equal(t, 2, l.limit)
+
+	// two slots are taken, a third acquire wait until one is released or ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.True(t, l.acquire(ctx))
+	assert.True(t, l.acquire(ctx))
+	assert.False(t, l.acquire(ctx))
+	l.release()
+	assert.True(t, l.acquire(context.Background()))
+
+	// additive increase, one more worker per limit successes
+	l.succeeded()
+	assert.Equal(t, 2, l.limit)
+	l.succeeded()
+	assert.Equal(t, 3, l.limit)
+	for i := 0; i < 100; i++ {
+		l.succeeded()
+	}
+	assert.Equal(t, 8, l.limit)
+	assert.Equal(t, 2, l.lowest)
+
+	var none *aimdLimiter
+	assert.True(t, none.acquire(ctx))
+	none.throttled()
+}
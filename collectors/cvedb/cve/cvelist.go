@@ -0,0 +1,50 @@
+package cve
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// OpenCVEList open a cve list v5 repo checkout directory or zip archive, to be used with WithCVEList
+// it return the record tree and a func to release it
+func OpenCVEList(cveListPath string) (fs.FS, func() error, error) {
+	if !strings.HasSuffix(cveListPath, ".zip") {
+		return os.DirFS(cveListPath), func() error { return nil }, nil
+	}
+	zr, err := zip.OpenReader(cveListPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	// github archives nest the repo under a single top level folder
+	matches, err := fs.Glob(zr, "*/cves")
+	if err != nil || len(matches) != 1 {
+		return zr, zr.Close, nil
+	}
+	sub, err := fs.Sub(zr, path.Dir(matches[0]))
+	if err != nil {
+		_ = zr.Close()
+		return nil, nil, err
+	}
+	return sub, zr.Close, nil
+}
+
+// cveListRecordPath return cve record path in cve list v5 layout: cves/<year>/<id prefix>xxx/<id>.json
+func cveListRecordPath(cveID string) (string, error) {
+	parts := strings.Split(cveID, "-")
+	if len(parts) != 3 || len(parts[2]) < 4 {
+		return "", fmt.Errorf("invalid cve id %s", cveID)
+	}
+	return path.Join("cves", parts[1], parts[2][:len(parts[2])-3]+"xxx", cveID+".json"), nil
+}
+
+func (c collector) readCVEList(cveID string) ([]byte, error) {
+	recordPath, err := cveListRecordPath(cveID)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(c.cveList, recordPath)
+}
@@ -0,0 +1,93 @@
+package cve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCveListRecordPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		cveID  string
+		want   string
+		hasErr bool
+	}{
+		{name: "four digits id", cveID: "CVE-2023-2431", want: "cves/2023/2xxx/CVE-2023-2431.json"},
+		{name: "five digits id", cveID: "CVE-2021-25735", want: "cves/2021/25xxx/CVE-2021-25735.json"},
+		{name: "seven digits id", cveID: "CVE-2018-1002105", want: "cves/2018/1002xxx/CVE-2018-1002105.json"},
+		{name: "invalid id", cveID: "GHSA-1234", hasErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cveListRecordPath(tt.cveID)
+			if tt.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseVulnDBDataFromCVEList(t *testing.T) {
+	cveList, closeFn, err := OpenCVEList("./testdata/cvelist")
+	assert.NoError(t, err)
+	defer func() {
+		_ = closeFn()
+	}()
+	b, err := os.ReadFile("./testdata/feed/single.json")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(b, WithCVEList(cveList))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+	assert.Equal(t, "k8s.io/kubelet", kvd.Cves[0].Component)
+	assert.Equal(t, []*Affected{{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.2"}}}}}}, kvd.Cves[0].Affected)
+}
+
+func TestParseVulnDBDataSourceFallback(t *testing.T) {
+	cveList, closeFn, err := OpenCVEList("./testdata/cvelist")
+	assert.NoError(t, err)
+	defer func() {
+		_ = closeFn()
+	}()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	b, err := os.ReadFile("./testdata/feed/single.json")
+	assert.NoError(t, err)
+
+	kvd, err := ParseVulnDBData(b, WithMitreURL(failing.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(kvd.Cves))
+
+	kvd, err = ParseVulnDBData(b, WithMitreURL(failing.URL), WithCVEList(cveList), WithSourcePriority(SourceAPI, SourceCVEList))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(kvd.Cves))
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+}
+
+func TestMitreRecordSourceFallback(t *testing.T) {
+	ts := newMitreServer(t)
+	cveList, closeFn, err := OpenCVEList("./testdata/cvelist")
+	assert.NoError(t, err)
+	defer func() {
+		_ = closeFn()
+	}()
+	c := newCollector(WithMitreURL(ts.URL), WithCVEList(cveList), WithSourcePriority(SourceCVEList, SourceAPI))
+	// CVE-2023-1004 is missing from the cve list but served by the api
+	record, err := c.mitreRecord(context.Background(), "CVE-2023-1004")
+	assert.NoError(t, err)
+	assert.Contains(t, string(record), "CVE-2023-1004")
+
+	_, err = c.mitreRecord(context.Background(), "CVE-2023-9999")
+	assert.ErrorContains(t, err, "cvelist source")
+	assert.ErrorContains(t, err, "api source")
+}
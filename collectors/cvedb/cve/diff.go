@@ -0,0 +1,116 @@
+package cve
+
+import (
+	"reflect"
+	"sort"
+)
+
+// DBDiff is the difference between two collections, cve ids are sorted
+type DBDiff struct {
+	Added   []string
+	Removed []string
+	// Ranges list the affected ranges changes of cves present in both collections
+	Ranges []RangeDiff
+}
+
+// RangeDiff is the affected ranges change of a cve between two collections, ranges are matched by
+// type and introduced version so a range gaining a fixed version is reported as modified
+type RangeDiff struct {
+	CveID    string
+	Added    []*Range
+	Removed  []*Range
+	Modified []RangeChange
+}
+
+// RangeChange is a range whose events changed between two collections
+type RangeChange struct {
+	Old *Range
+	New *Range
+}
+
+// Diff return the cves added and removed from prev to cur and the range changes of cves in both
+func Diff(prev, cur *K8sVulnDB) *DBDiff {
+	oldCves, newCves := cvesByID(prev), cvesByID(cur)
+	diff := &DBDiff{}
+	for id, nv := range newCves {
+		ov, ok := oldCves[id]
+		if !ok {
+			diff.Added = append(diff.Added, id)
+			continue
+		}
+		if rd := diffRanges(ov, nv); rd != nil {
+			diff.Ranges = append(diff.Ranges, *rd)
+		}
+	}
+	for id := range oldCves {
+		if _, ok := newCves[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Ranges, func(i, j int) bool {
+		return diff.Ranges[i].CveID < diff.Ranges[j].CveID
+	})
+	return diff
+}
+
+func cvesByID(db *K8sVulnDB) map[string]*Vulnerability {
+	cves := make(map[string]*Vulnerability)
+	if db == nil {
+		return cves
+	}
+	for _, v := range db.Cves {
+		cves[v.ID] = v
+	}
+	return cves
+}
+
+// diffRanges return the range changes of a cve, nil when its ranges are unchanged
+func diffRanges(prev, cur *Vulnerability) *RangeDiff {
+	oldRanges, oldKeys := rangesByStart(prev)
+	newRanges, newKeys := rangesByStart(cur)
+	rd := &RangeDiff{CveID: cur.ID}
+	for _, k := range newKeys {
+		nr := newRanges[k]
+		or, ok := oldRanges[k]
+		switch {
+		case !ok:
+			rd.Added = append(rd.Added, nr)
+		case !reflect.DeepEqual(or.Events, nr.Events):
+			rd.Modified = append(rd.Modified, RangeChange{Old: or, New: nr})
+		}
+	}
+	for _, k := range oldKeys {
+		if _, ok := newRanges[k]; !ok {
+			rd.Removed = append(rd.Removed, oldRanges[k])
+		}
+	}
+	if len(rd.Added) == 0 && len(rd.Removed) == 0 && len(rd.Modified) == 0 {
+		return nil
+	}
+	return rd
+}
+
+// rangesByStart index the cve ranges by type and introduced version, keys are returned in ranges order
+func rangesByStart(v *Vulnerability) (map[[2]string]*Range, [][2]string) {
+	ranges := make(map[[2]string]*Range)
+	keys := make([][2]string, 0)
+	for _, a := range v.Affected {
+		for _, r := range a.Ranges {
+			var introduced string
+			for _, e := range r.Events {
+				if len(e.Introduced) > 0 {
+					introduced = e.Introduced
+					break
+				}
+			}
+			k := [2]string{r.RangeType, introduced}
+			if _, ok := ranges[k]; !ok {
+				keys = append(keys, k)
+			}
+			ranges[k] = r
+		}
+	}
+	return ranges, keys
+}
@@ -0,0 +1,26 @@
+package cve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	prev := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/diff-previous.json")}
+	cur := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/diff-current.json")}
+	assert.Equal(t, &DBDiff{
+		Added:   []string{"CVE-2023-1004"},
+		Removed: []string{"CVE-2023-1002"},
+		Ranges: []RangeDiff{{
+			CveID: "CVE-2023-1001",
+			Added: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.25.0"}, {Fixed: "1.25.2"}}}},
+			Modified: []RangeChange{{
+				Old: &Range{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {LastAffected: "1.24.5"}}},
+				New: &Range{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.6"}}},
+			}},
+		}},
+	}, Diff(prev, cur))
+
+	assert.Equal(t, &DBDiff{}, Diff(prev, prev))
+}
@@ -0,0 +1,95 @@
+package cve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// reasons a cve of a previous database is missing from a new one
+const (
+	// DisappearedWithdrawn is for cves no collection step dropped, gone from the feed or withdrawn upstream
+	DisappearedWithdrawn = "withdrawn"
+	// DisappearedParseFailure is for cves skipped for lack of usable data or failing validation, usually a
+	// parse regression
+	DisappearedParseFailure = "parse-failure"
+	// DisappearedFiltered is for cves skipped by a collection option, e.g. WithMinYear
+	DisappearedFiltered = "filtered"
+)
+
+// DisappearedCve is a cve of a previous database missing from a new one, Detail is the skip reason or the
+// validation errors of the cve
+type DisappearedCve struct {
+	CveID  string
+	Reason string
+	Detail string
+}
+
+// DisappearedCves return the cves of previous missing from current in previous order, with a best-effort reason
+// from the stats of the collection of current. without stats every missing cve is reported withdrawn
+func DisappearedCves(previous, current *K8sVulnDB, stats *CollectStats) []DisappearedCve {
+	present := make(map[string]bool)
+	for _, v := range current.Cves {
+		present[v.ID] = true
+	}
+	skipped := make(map[string]SkippedCve)
+	errors := make(map[string][]string)
+	if stats != nil {
+		stats.mu.Lock()
+		for _, s := range stats.SkippedCves {
+			skipped[s.CveID] = s
+		}
+		for _, issue := range stats.ValidationIssues {
+			if !issue.Warning {
+				errors[issue.CveID] = append(errors[issue.CveID], issue.Message)
+			}
+		}
+		stats.mu.Unlock()
+	}
+	disappeared := make([]DisappearedCve, 0)
+	for _, v := range previous.Cves {
+		if present[v.ID] {
+			continue
+		}
+		d := DisappearedCve{CveID: v.ID, Reason: DisappearedWithdrawn}
+		if s, ok := skipped[v.ID]; ok {
+			d.Reason, d.Detail = DisappearedParseFailure, s.Reason
+			if s.Filtered {
+				d.Reason = DisappearedFiltered
+			}
+		} else if messages, ok := errors[v.ID]; ok {
+			d.Reason, d.Detail = DisappearedParseFailure, strings.Join(messages, ", ")
+		}
+		disappeared = append(disappeared, d)
+	}
+	return disappeared
+}
+
+// LoadDir read a database written by WriteToDir or WriteBySeverity in the json or osv format
+func LoadDir(root string) (*K8sVulnDB, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	db := &K8sVulnDB{Cves: make([]*Vulnerability, 0)}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(root, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		records, err := fileRecords(b)
+		if err != nil {
+			return nil, wrapError(ErrDecode, fmt.Errorf("database file %s: %w", e.Name(), err))
+		}
+		for _, record := range records {
+			if v, _ := decodeRecord(record); v != nil {
+				db.Cves = append(db.Cves, v)
+			}
+		}
+	}
+	return db, nil
+}
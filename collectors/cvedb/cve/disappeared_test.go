@@ -0,0 +1,46 @@
+package cve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisappearedCves(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/min-year.json")
+	assert.NoError(t, err)
+	stats := &CollectStats{}
+	current, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithMinYear(2016), WithStats(stats))
+	assert.NoError(t, err)
+	// an invalid cve dropped from partial results
+	stats.addValidation(1, []ValidationIssue{{CveID: "CVE-2023-1002", Code: IssueMissingUrls, Message: "Urls is mssing"}})
+	stats.addSkipped(SkippedCve{CveID: "CVE-2023-1003", Reason: "no affected versions on mitre record nor feed content text"})
+
+	previous := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/disappeared-previous.json")}
+	assert.Equal(t, []DisappearedCve{
+		{CveID: "CVE-2015-1001", Reason: DisappearedFiltered, Detail: "before min year 2016"},
+		{CveID: "CVE-2023-1002", Reason: DisappearedParseFailure, Detail: "Urls is mssing"},
+		{CveID: "CVE-2023-1003", Reason: DisappearedParseFailure, Detail: "no affected versions on mitre record nor feed content text"},
+		{CveID: "CVE-2023-1004", Reason: DisappearedWithdrawn},
+	}, DisappearedCves(previous, current, stats))
+
+	assert.Equal(t, []DisappearedCve{{CveID: "CVE-2023-1004", Reason: DisappearedWithdrawn}},
+		DisappearedCves(&K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/disappeared-previous.json", "CVE-2023-1004")}, current, nil))
+}
+
+func TestLoadDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "cves")
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001", "CVE-2023-1002")}
+	assert.NoError(t, WriteToDir(db, root, FormatOSV))
+	got, err := LoadDir(root)
+	assert.NoError(t, err)
+	ids := make([]string, 0)
+	for _, v := range got.Cves {
+		ids = append(ids, v.ID)
+	}
+	assert.Equal(t, []string{"CVE-2023-1001", "CVE-2023-1002"}, ids)
+	assert.Equal(t, "k8s.io/kubelet", got.Cves[0].Component)
+}
@@ -0,0 +1,41 @@
+package cve
+
+import (
+	"errors"
+)
+
+var (
+	// ErrDecode is returned when feed or mitre data can not be decoded
+	ErrDecode = errors.New("decode error")
+	// ErrUnsupportedURL is returned when a feed item external url is not a supported cve source
+	ErrUnsupportedURL = errors.New("unsupported external url")
+	// ErrUpstream is returned when an upstream source can not be fetched or answer with a failure
+	ErrUpstream = errors.New("upstream error")
+	// ErrUnrecognizedVersion is returned in strict mode when a mitre version shape can not be interpreted
+	ErrUnrecognizedVersion = errors.New("unrecognized mitre version")
+	// ErrTooFewCves is returned when fewer cves than set with WithMinCves are collected
+	ErrTooFewCves = errors.New("too few cves collected")
+)
+
+// kindError tag an error with a sentinel kind while keeping the original error in the chain,
+// so both errors.Is(err, kind) and errors.Is(err, cause) match
+type kindError struct {
+	kind error
+	err  error
+}
+
+func wrapError(kind error, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+func (e *kindError) Error() string {
+	return e.kind.Error() + ": " + e.err.Error()
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
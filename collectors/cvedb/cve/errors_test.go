@@ -0,0 +1,64 @@
+package cve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/CVE-2023-1001" {
+			_, _ = w.Write([]byte("{not json"))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	c := newCollector(WithMitreURL(ts.URL))
+	cveURL := "https://www.cve.org/cverecord?id="
+
+	tests := []struct {
+		name    string
+		fn      func() error
+		wantErr error
+	}{
+		{name: "upstream failure", wantErr: ErrUpstream, fn: func() error {
+			_, err := c.parseMitreCve(context.Background(), cveURL+"CVE-2023-1002", "CVE-2023-1002", nil)
+			return err
+		}},
+		{name: "mitre decode failure", wantErr: ErrDecode, fn: func() error {
+			_, err := c.parseMitreCve(context.Background(), cveURL+"CVE-2023-1001", "CVE-2023-1001", nil)
+			return err
+		}},
+		{name: "unsupported url", wantErr: ErrUnsupportedURL, fn: func() error {
+			_, err := c.parseMitreCve(context.Background(), "https://example.com/CVE-2023-1001", "CVE-2023-1001", nil)
+			return err
+		}},
+		{name: "feed decode failure", wantErr: ErrDecode, fn: func() error {
+			_, err := ParseVulnDBData([]byte("not json"))
+			return err
+		}},
+		{name: "feed without items", wantErr: ErrDecode, fn: func() error {
+			_, err := ParseVulnDBData([]byte(`{"version":"1"}`))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fn()
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
+
+func TestWrapErrorKeepCause(t *testing.T) {
+	err := wrapError(ErrUpstream, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, ErrUpstream)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "upstream error: context deadline exceeded", err.Error())
+}
@@ -0,0 +1,35 @@
+package cve
+
+import (
+	"context"
+	"fmt"
+)
+
+// DerivationTrace record each decision made while deriving a cve affected ranges from its mitre record
+type DerivationTrace struct {
+	CveID    string
+	Steps    []string
+	Affected []*Affected
+}
+
+// record append a decision step to the trace, a nil trace record nothing
+func (t *DerivationTrace) record(format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, fmt.Sprintf(format, args...))
+}
+
+// Explain re-parse a single cve mitre record and return the trace of how its affected ranges were derived
+func Explain(cveID string, opts ...option) (*DerivationTrace, error) {
+	c := newCollector(opts...)
+	ctx, cancel := c.withTimeout(context.Background())
+	defer cancel()
+	trace := &DerivationTrace{CveID: cveID}
+	vulnerability, err := c.parseMitreCve(ctx, cveRecordURL(cveID), cveID, trace)
+	if err != nil {
+		return nil, err
+	}
+	trace.Affected = GetAffectedEvents(vulnerability)
+	return trace, nil
+}
@@ -0,0 +1,22 @@
+package cve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	ts := newMitreServer(t)
+	trace, err := Explain("CVE-2023-1001", WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, "CVE-2023-1001", trace.CveID)
+	assert.Equal(t, []string{
+		`version "1.24.0" lessThan "1.24.2" lessThanOrEqual "": affected`,
+		`lessThan branch: introduced "1.24.0" fixed "1.24.2"`,
+	}, trace.Steps)
+	assert.Equal(t, []*Affected{{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.2"}}}}}}, trace.Affected)
+
+	_, err = Explain("CVE-2023-9999", WithMitreURL(ts.URL))
+	assert.Error(t, err)
+}
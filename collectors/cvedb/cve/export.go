@@ -0,0 +1,423 @@
+package cve
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatJSON is the collector native json format
+	FormatJSON = "json"
+	// FormatOSV is the Open Source Vulnerability json format
+	FormatOSV = "osv"
+	// FormatYAML is the native format encoded as yaml with sorted keys
+	FormatYAML = "yaml"
+
+	// unknownSeverity name the WriteBySeverity file of vulnerabilities without a severity
+	unknownSeverity = "unknown"
+
+	osvSchemaVersion = "1.5.0"
+	osvEcosystem     = "kubernetes"
+)
+
+// OutputFormats list the supported export formats
+var OutputFormats = []string{FormatJSON, FormatOSV, FormatYAML}
+
+// OSV is a vulnerability in the Open Source Vulnerability format
+type OSV struct {
+	SchemaVersion    string                 `json:"schema_version"`
+	ID               string                 `json:"id"`
+	Modified         string                 `json:"modified"`
+	Published        string                 `json:"published,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Details          string                 `json:"details,omitempty"`
+	Severity         []OSVSeverity          `json:"severity,omitempty"`
+	Affected         []OSVAffected          `json:"affected,omitempty"`
+	References       []OSVReference         `json:"references,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type OSVAffected struct {
+	Package          OSVPackage             `json:"package"`
+	Ranges           []*Range               `json:"ranges,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type OSVPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type OSVReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ToOSV convert the vulnerability to the OSV format, collector severity is kept in database_specific
+func (v *Vulnerability) ToOSV() *OSV {
+	o := &OSV{
+		SchemaVersion: osvSchemaVersion,
+		ID:            v.ID,
+		Modified:      v.CreatedAt,
+		Published:     v.CreatedAt,
+		Summary:       v.Summary,
+		Details:       v.Description,
+	}
+	if len(v.CvssV3.Vector) > 0 {
+		o.Severity = []OSVSeverity{{Type: osvSeverityType(v), Score: v.CvssV3.Vector}}
+	}
+	for _, a := range v.Affected {
+		o.Affected = append(o.Affected, OSVAffected{
+			Package:          OSVPackage{Ecosystem: osvEcosystem, Name: v.Component},
+			Ranges:           a.Ranges,
+			DatabaseSpecific: a.DatabaseSpecific,
+		})
+	}
+	for _, u := range v.Urls {
+		o.References = append(o.References, OSVReference{Type: "WEB", URL: u})
+	}
+	if len(v.Severity) > 0 || len(v.DatabaseSpecific) > 0 {
+		o.DatabaseSpecific = make(map[string]interface{})
+		for k, val := range v.DatabaseSpecific {
+			o.DatabaseSpecific[k] = val
+		}
+		if len(v.Severity) > 0 {
+			o.DatabaseSpecific["severity"] = v.Severity
+		}
+	}
+	return o
+}
+
+// osvSeverityType return the OSV severity type of the cvss version used for scoring, the vector prefix is used
+// for vulnerabilities collected without a cvss version
+func osvSeverityType(v *Vulnerability) string {
+	if strings.HasPrefix(v.CvssVersion, "4") || (len(v.CvssVersion) == 0 && strings.HasPrefix(v.CvssV3.Vector, "CVSS:4")) {
+		return "CVSS_V4"
+	}
+	return "CVSS_V3"
+}
+
+// Marshal encode the vulnerability in format, json based formats are tab indented
+func Marshal(v *Vulnerability, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON, "":
+		return marshalIndent(v)
+	case FormatOSV:
+		return marshalIndent(v.ToOSV())
+	case FormatYAML:
+		return marshalYAML(v)
+	}
+	return nil, ValidateOutputFormat(format)
+}
+
+// ValidateOutputFormat check format is one of OutputFormats
+func ValidateOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q, supported formats are %v", format, OutputFormats)
+}
+
+// FileExtension return the file extension of format
+func FileExtension(format string) string {
+	if format == FormatYAML {
+		return "yaml"
+	}
+	return "json"
+}
+
+// WriteToDir replace root content with one file per db vulnerability (e.g. CVE-2023-1001.json) in format,
+// written by one worker per cpu. root is replaced at once, a failed write leave the previous content in place
+func WriteToDir(db *K8sVulnDB, root, format string) error {
+	return WriteToDirWorkers(db, root, format, runtime.NumCPU())
+}
+
+// WriteToDirWorkers is WriteToDir with files written by up to workers goroutines, the errors of every failed
+// file are returned in db order
+func WriteToDirWorkers(db *K8sVulnDB, root, format string, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+	return writeDirAtomic(root, func(dir string) error {
+		errs := make([]error, len(db.Cves))
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					errs[i] = writeCveFile(dir, db.Cves[i], format)
+				}
+			}()
+		}
+		for i := range db.Cves {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		var result error
+		for _, err := range errs {
+			if err != nil {
+				result = multierror.Append(result, err)
+			}
+		}
+		return result
+	})
+}
+
+// writeCveFile write v in format to its file in dir
+func writeCveFile(dir string, v *Vulnerability, format string) error {
+	data, err := Marshal(v, format)
+	if err != nil {
+		return fmt.Errorf("cve %s: %w", v.ID, err)
+	}
+	filePath := filepath.Join(dir, fmt.Sprintf("%s.%s", v.ID, FileExtension(format)))
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	return nil
+}
+
+// WriteBySeverity replace root content with db vulnerabilities grouped by severity, one json array per severity
+// (e.g. critical.json, high.json) keeping db order, and unknown.json for vulnerabilities without a severity.
+// root is replaced at once, a failed write leave the previous content in place
+func WriteBySeverity(db *K8sVulnDB, root string) error {
+	groups := make(map[string][]*Vulnerability)
+	for _, v := range db.Cves {
+		name := severityFileName(v.Severity)
+		groups[name] = append(groups[name], v)
+	}
+	return writeDirAtomic(root, func(dir string) error {
+		for name, cves := range groups {
+			data, err := marshalIndent(cves)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(dir, name+".json"), data, 0644); err != nil {
+				return fmt.Errorf("write error: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpsertDir update root in place with one json file per db vulnerability, only files whose content changed are
+// written so unchanged files keep their mtime, and json files of vulnerabilities no longer in db are removed
+func UpsertDir(db *K8sVulnDB, root string) error {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("mkdir error: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, v := range db.Cves {
+		data, err := Marshal(v, FormatJSON)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s.%s", v.ID, FileExtension(FormatJSON))
+		names[name] = true
+		filePath := filepath.Join(root, name)
+		if existing, err := os.ReadFile(filePath); err == nil && bytes.Equal(existing, data) {
+			continue
+		}
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" || names[e.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(root, e.Name())); err != nil {
+			return fmt.Errorf("remove error: %w", err)
+		}
+	}
+	return nil
+}
+
+// csvHeader is the header row of WriteCSV
+var csvHeader = []string{"id", "component", "severity", "score", "introduced", "fixed", "url"}
+
+// WriteCSV write db to w as csv for spreadsheet triage, one row per range so a multi range cve span several
+// rows and a cve without ranges get one row with empty bounds. url is the cve first reference
+func WriteCSV(db *K8sVulnDB, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, v := range db.Cves {
+		var score, url string
+		if v.CvssV3.Score > 0 {
+			score = strconv.FormatFloat(v.CvssV3.Score, 'f', 1, 64)
+		}
+		if len(v.Urls) > 0 {
+			url = v.Urls[0]
+		}
+		rows := 0
+		for _, a := range v.Affected {
+			for _, r := range a.Ranges {
+				introduced, fixed := rangeBounds(r)
+				if err := cw.Write([]string{v.ID, v.Component, v.Severity, score, introduced, fixed, url}); err != nil {
+					return err
+				}
+				rows++
+			}
+		}
+		if rows == 0 {
+			if err := cw.Write([]string{v.ID, v.Component, v.Severity, score, "", "", url}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// rangeBounds return the first introduced and fixed events of r
+func rangeBounds(r *Range) (string, string) {
+	var introduced, fixed string
+	for _, e := range r.Events {
+		if len(introduced) == 0 {
+			introduced = e.Introduced
+		}
+		if len(fixed) == 0 {
+			fixed = e.Fixed
+		}
+	}
+	return introduced, fixed
+}
+
+// writeDirAtomic run write on a temp directory next to root then swap it in place of root, root is never
+// partially written, on failure the temp directory is removed and root untouched. the swap takes two renames,
+// root moved aside then the temp directory moved in, so root is briefly missing in between. a crash in that gap
+// leave root missing until the next write restore it, see recoverDirAtomic
+func writeDirAtomic(root string, write func(dir string) error) error {
+	parent, base := filepath.Split(filepath.Clean(root))
+	if len(parent) == 0 {
+		parent = "."
+	}
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("mkdir error: %w", err)
+	}
+	if err := recoverDirAtomic(parent, base); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(parent, "."+base+"-tmp-")
+	if err != nil {
+		return fmt.Errorf("mkdir error: %w", err)
+	}
+	if err := write(tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	var previous string
+	if _, err := os.Stat(root); err == nil {
+		previous = tmp + "-previous"
+		if err := os.Rename(root, previous); err != nil {
+			_ = os.RemoveAll(tmp)
+			return fmt.Errorf("failed to move %s aside: %w", root, err)
+		}
+	}
+	if err := os.Rename(tmp, root); err != nil {
+		if len(previous) > 0 {
+			_ = os.Rename(previous, root)
+		}
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("failed to replace %s: %w", root, err)
+	}
+	if len(previous) > 0 {
+		return os.RemoveAll(previous)
+	}
+	return nil
+}
+
+// recoverDirAtomic clean up after a writeDirAtomic interrupted by a crash: a root moved aside but never
+// replaced is moved back, leftover temp and moved aside directories are removed
+func recoverDirAtomic(parent, base string) error {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return err
+	}
+	root := filepath.Join(parent, base)
+	_, err = os.Stat(root)
+	missing := os.IsNotExist(err)
+	prefix := "." + base + "-tmp-"
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		leftover := filepath.Join(parent, e.Name())
+		if missing && strings.HasSuffix(e.Name(), "-previous") {
+			if err := os.Rename(leftover, root); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", root, err)
+			}
+			missing = false
+			continue
+		}
+		if err := os.RemoveAll(leftover); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// severityFileName return the lower cased severity, unknownSeverity when missing or not a plain word
+func severityFileName(severity string) string {
+	name := strings.ToLower(strings.TrimSpace(severity))
+	if len(name) == 0 || strings.Trim(name, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return unknownSeverity
+	}
+	return name
+}
+
+func marshalIndent(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, data, "", "\t"); err != nil {
+		return nil, fmt.Errorf("failed ro format json: %w", err)
+	}
+	return prettyJSON.Bytes(), nil
+}
+
+// marshalYAML encode v through its json form so yaml keys match the json ones and are sorted
+func marshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
@@ -0,0 +1,276 @@
+package cve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshal(t *testing.T) {
+	v := loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0]
+	v.DatabaseSpecific = map[string]interface{}{"zone": "b", "area": "a"}
+	tests := []struct {
+		name      string
+		format    string
+		unmarshal func([]byte, interface{}) error
+		wantKeys  []string
+	}{
+		{name: "json", format: FormatJSON, unmarshal: json.Unmarshal,
+			wantKeys: []string{"advisory_refs", "affected", "component", "created_at", "cvssv3", "database_specific", "details", "id", "references", "severity", "summary"}},
+		{name: "osv", format: FormatOSV, unmarshal: json.Unmarshal,
+			wantKeys: []string{"affected", "database_specific", "details", "id", "modified", "published", "references", "schema_version", "severity", "summary"}},
+		{name: "yaml", format: FormatYAML, unmarshal: yaml.Unmarshal,
+			wantKeys: []string{"advisory_refs", "affected", "component", "created_at", "cvssv3", "database_specific", "details", "id", "references", "severity", "summary"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(v, tt.format)
+			assert.NoError(t, err)
+			var got map[string]interface{}
+			assert.NoError(t, tt.unmarshal(data, &got))
+			keys := make([]string, 0, len(got))
+			for k := range got {
+				keys = append(keys, k)
+			}
+			assert.ElementsMatch(t, tt.wantKeys, keys)
+			assert.Equal(t, "CVE-2023-1001", got["id"])
+		})
+	}
+}
+
+func TestMarshalOSV(t *testing.T) {
+	data, err := Marshal(loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0], FormatOSV)
+	assert.NoError(t, err)
+	var got OSV
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, OSVPackage{Ecosystem: osvEcosystem, Name: "k8s.io/kubelet"}, got.Affected[0].Package)
+	assert.Equal(t, []OSVSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:L/AC:L/PR:H/UI:N/S:U/C:L/I:L/A:N"}}, got.Severity)
+	assert.Equal(t, "Low", got.DatabaseSpecific["severity"])
+}
+
+func TestMarshalYAMLSortedKeys(t *testing.T) {
+	v := loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0]
+	v.DatabaseSpecific = map[string]interface{}{"zone": "b", "area": "a", "middle": "c"}
+	first, err := Marshal(v, FormatYAML)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		again, err := Marshal(v, FormatYAML)
+		assert.NoError(t, err)
+		assert.Equal(t, string(first), string(again))
+	}
+	assert.Contains(t, string(first), "database_specific:\n    area: a\n    middle: c\n    zone: b\n")
+}
+
+func TestMarshalUnsupportedFormat(t *testing.T) {
+	_, err := Marshal(loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0], "xml")
+	assert.ErrorContains(t, err, "unsupported output format \"xml\"")
+}
+
+func TestMarshalOSVSeverityType(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{name: "v3.1", id: "CVE-2023-1001", want: "CVSS_V3"},
+		{name: "v4.0", id: "CVE-2023-1016", want: "CVSS_V4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := loadCves(t, "./testdata/vulndb/export.json", tt.id)[0]
+			assert.Equal(t, []OSVSeverity{{Type: tt.want, Score: v.CvssV3.Vector}}, v.ToOSV().Severity)
+		})
+	}
+}
+
+func TestWriteBySeverity(t *testing.T) {
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/severity.json")}
+	root := filepath.Join(t.TempDir(), "severity")
+	assert.NoError(t, WriteBySeverity(db, root))
+
+	entries, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	got := make(map[string][]string)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(root, e.Name()))
+		assert.NoError(t, err)
+		var cves []*Vulnerability
+		assert.NoError(t, json.Unmarshal(data, &cves))
+		for _, v := range cves {
+			got[e.Name()] = append(got[e.Name()], v.ID)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"critical.json": {"CVE-2023-1002"},
+		"high.json":     {"CVE-2023-1001", "CVE-2023-1003"},
+		"unknown.json":  {"CVE-2023-1004"},
+	}, got)
+}
+
+func TestWriteToDirAtomic(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "cves")
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001", "CVE-2023-1002")}
+	assert.NoError(t, WriteToDir(db, root, FormatJSON))
+	previous, err := os.ReadFile(filepath.Join(root, "CVE-2023-1001.json"))
+	assert.NoError(t, err)
+
+	// the second vulnerability can not be encoded, failing the write after the first file is written
+	failing := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/severity.json", "CVE-2023-1003", "CVE-2023-1004")}
+	failing.Cves[1].DatabaseSpecific = map[string]interface{}{"unencodable": func() {}}
+	assert.Error(t, WriteToDir(failing, root, FormatJSON))
+	assert.Error(t, WriteBySeverity(failing, root))
+
+	entries, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"CVE-2023-1001.json", "CVE-2023-1002.json"}, names)
+	got, err := os.ReadFile(filepath.Join(root, "CVE-2023-1001.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, previous, got)
+	// no temp directory is left behind
+	siblings, err := os.ReadDir(filepath.Dir(root))
+	assert.NoError(t, err)
+	assert.Len(t, siblings, 1)
+
+	assert.NoError(t, WriteToDir(&K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1003")}, root, FormatYAML))
+	entries, err = os.ReadDir(root)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "CVE-2023-1003.yaml", entries[0].Name())
+}
+
+func TestWriteToDirAtomicRecover(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "cves")
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")}
+	assert.NoError(t, WriteToDir(db, root, FormatJSON))
+
+	// a crash between moving root aside and moving the new content in, with a temp directory left behind
+	assert.NoError(t, os.Rename(root, filepath.Join(parent, ".cves-tmp-1-previous")))
+	assert.NoError(t, os.Mkdir(filepath.Join(parent, ".cves-tmp-2"), 0755))
+	assert.NoError(t, recoverDirAtomic(parent, "cves"))
+	_, err := os.Stat(filepath.Join(root, "CVE-2023-1001.json"))
+	assert.NoError(t, err)
+	siblings, err := os.ReadDir(parent)
+	assert.NoError(t, err)
+	assert.Len(t, siblings, 1)
+
+	// a leftover moved aside directory next to root is stale and removed by the next write
+	assert.NoError(t, os.Mkdir(filepath.Join(parent, ".cves-tmp-3-previous"), 0755))
+	assert.NoError(t, WriteToDir(&K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1002")}, root, FormatJSON))
+	siblings, err = os.ReadDir(parent)
+	assert.NoError(t, err)
+	assert.Len(t, siblings, 1)
+	_, err = os.Stat(filepath.Join(root, "CVE-2023-1002.json"))
+	assert.NoError(t, err)
+}
+
+func TestUpsertDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "cves")
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001", "CVE-2023-1002", "CVE-2023-1004")}
+	assert.NoError(t, UpsertDir(db, root))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("k8s vulndb cves"), 0644))
+	past := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"CVE-2023-1001.json", "CVE-2023-1002.json", "README.md"} {
+		assert.NoError(t, os.Chtimes(filepath.Join(root, name), past, past))
+	}
+
+	// CVE-2023-1002 changed, CVE-2023-1003 is new and CVE-2023-1004 is gone
+	db = &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001", "CVE-2023-1003")}
+	db.Cves = append(db.Cves, loadCves(t, "./testdata/vulndb/severity.json", "CVE-2023-1002")...)
+	assert.NoError(t, UpsertDir(db, root))
+
+	entries, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	touched := make(map[string]bool)
+	for _, e := range entries {
+		info, err := e.Info()
+		assert.NoError(t, err)
+		touched[e.Name()] = !info.ModTime().Equal(past)
+	}
+	assert.Equal(t, map[string]bool{
+		"CVE-2023-1001.json": false,
+		"CVE-2023-1002.json": true,
+		"CVE-2023-1003.json": true,
+		"README.md":          false,
+	}, touched)
+	data, err := os.ReadFile(filepath.Join(root, "CVE-2023-1002.json"))
+	assert.NoError(t, err)
+	var got Vulnerability
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "Critical", got.Severity)
+}
+
+func TestWriteCSV(t *testing.T) {
+	// CVE-2023-1002 has a reference with a comma and quotes which must be quoted and escaped
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/csv.json")}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(db, &buf))
+	want, err := os.ReadFile("./testdata/export.csv.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), buf.String())
+}
+
+// testDB return a db of n copies of a valid cve with distinct ids
+func testDB(t testing.TB, n int) *K8sVulnDB {
+	valid := loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0]
+	db := &K8sVulnDB{Cves: make([]*Vulnerability, 0, n)}
+	for i := 0; i < n; i++ {
+		v := *valid
+		v.ID = fmt.Sprintf("CVE-2023-%d", 10000+i)
+		db.Cves = append(db.Cves, &v)
+	}
+	return db
+}
+
+func TestWriteToDirWorkers(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "cves")
+	db := testDB(t, 200)
+	assert.NoError(t, WriteToDirWorkers(db, root, FormatOSV, 8))
+	entries, err := os.ReadDir(root)
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(db.Cves))
+	for _, v := range db.Cves {
+		want, err := Marshal(v, FormatOSV)
+		assert.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join(root, v.ID+".json"))
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// errors are aggregated in db order whichever worker fail first
+	unencodable := map[string]interface{}{"unencodable": func() {}}
+	db.Cves[150].DatabaseSpecific = unencodable
+	db.Cves[3].DatabaseSpecific = unencodable
+	err = WriteToDirWorkers(db, root, FormatJSON, 8)
+	assert.ErrorContains(t, err, "2 errors occurred")
+	assert.Regexp(t, `(?s)cve CVE-2023-10003: .*cve CVE-2023-10150: `, err.Error())
+	entries, err = os.ReadDir(root)
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(db.Cves))
+}
+
+func BenchmarkWriteToDir(b *testing.B) {
+	db := testDB(b, 1000)
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			root := filepath.Join(b.TempDir(), "cves")
+			for i := 0; i < b.N; i++ {
+				if err := WriteToDirWorkers(db, root, FormatJSON, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
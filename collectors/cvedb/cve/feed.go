@@ -0,0 +1,96 @@
+package cve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FeedItem is a single k8s vulndb feed entry, ID may list several cve ids (e.g. "CVE-2023-1001, CVE-2023-1002")
+type FeedItem struct {
+	ID            string
+	URL           string
+	ExternalURL   string
+	Summary       string
+	ContentText   string
+	DatePublished string
+}
+
+// FeedParser decode a k8s vulndb feed into its items, so a new feed schema only need a new parser
+type FeedParser interface {
+	Parse([]byte) ([]FeedItem, error)
+}
+
+// JSONFeedParser parse the official k8s vulndb feed, a json feed (https://jsonfeed.org) document, or the same
+// items served as newline delimited json, one item object per line
+type JSONFeedParser struct{}
+
+// Parse decode the json feed items, missing item fields are left empty
+func (JSONFeedParser) Parse(data []byte) ([]FeedItem, error) {
+	values, err := jsonValues(data)
+	if err != nil {
+		return nil, wrapError(ErrDecode, fmt.Errorf("k8s vulndb feed: %w", err))
+	}
+	var items []interface{}
+	if db, ok := values[0].(map[string]interface{}); ok && len(values) == 1 {
+		if items, ok = db["items"].([]interface{}); !ok {
+			if _, ok := db["id"]; !ok {
+				return nil, fmt.Errorf("%w: k8s vulndb feed items are missing", ErrDecode)
+			}
+			// a single item ndjson feed
+			items = values
+		}
+	} else {
+		items = values
+	}
+	feedItems := make([]FeedItem, 0, len(items))
+	for n, item := range items {
+		i, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: k8s vulndb feed item #%d is not an object", ErrDecode, n)
+		}
+		str := func(key string) string {
+			s, _ := i[key].(string)
+			return s
+		}
+		feedItems = append(feedItems, FeedItem{
+			ID:            str("id"),
+			URL:           str("url"),
+			ExternalURL:   str("external_url"),
+			Summary:       str("summary"),
+			ContentText:   str("content_text"),
+			DatePublished: str("date_published"),
+		})
+	}
+	return feedItems, nil
+}
+
+// jsonValues decode the consecutive json values of data, a single one for a json feed document and one per
+// line for ndjson
+func jsonValues(data []byte) ([]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	values := make([]interface{}, 0)
+	for {
+		var v interface{}
+		err := d.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return values, nil
+}
+
+// WithFeedParser set the parser decoding the k8s vulndb feed, default to JSONFeedParser
+func WithFeedParser(parser FeedParser) option {
+	return func(o *options) {
+		o.feedParser = parser
+	}
+}
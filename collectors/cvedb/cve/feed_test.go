@@ -0,0 +1,74 @@
+package cve
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lineFeedParser is a fake feed schema, one "id|url|summary|content text" item per line
+type lineFeedParser struct{}
+
+func (lineFeedParser) Parse(data []byte) ([]FeedItem, error) {
+	items := make([]FeedItem, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Split(line, "|")
+		items = append(items, FeedItem{ID: fields[0], URL: fields[1], Summary: fields[2], ContentText: fields[3], DatePublished: "2023-06-15T14:42:32Z"})
+	}
+	return items, nil
+}
+
+func TestParseVulnDBDataFeedParser(t *testing.T) {
+	ts := newMitreServer(t)
+	feed := "CVE-2023-1001|https://github.com/kubernetes/kubernetes/issues/1001|Bypass of seccomp profile enforcement|A security issue was discovered in kubelet\n"
+
+	kvd, err := ParseVulnDBData([]byte(feed), WithMitreURL(ts.URL), WithFeedParser(lineFeedParser{}))
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 1)
+	assert.Equal(t, "CVE-2023-1001", kvd.Cves[0].ID)
+	assert.Equal(t, "k8s.io/kubelet", kvd.Cves[0].Component)
+	assert.Equal(t, "Bypass of seccomp profile enforcement", kvd.Cves[0].Summary)
+
+	// the default json feed parser can not decode another schema
+	_, err = ParseVulnDBData([]byte(feed), WithMitreURL(ts.URL))
+	assert.ErrorIs(t, err, ErrDecode)
+}
+
+func TestJSONFeedParser(t *testing.T) {
+	items, err := JSONFeedParser{}.Parse([]byte(`{"items": [{"id": "CVE-2023-1001", "url": "https://github.com/kubernetes/kubernetes/issues/1001", "summary": "Bypass"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []FeedItem{{ID: "CVE-2023-1001", URL: "https://github.com/kubernetes/kubernetes/issues/1001", Summary: "Bypass"}}, items)
+
+	_, err = JSONFeedParser{}.Parse([]byte(`{"items": ["CVE-2023-1001"]}`))
+	assert.ErrorIs(t, err, ErrDecode)
+	_, err = JSONFeedParser{}.Parse([]byte(`{"version": "https://jsonfeed.org/version/1.1"}`))
+	assert.ErrorIs(t, err, ErrDecode)
+
+	items, err = JSONFeedParser{}.Parse([]byte(`{"id": "CVE-2023-1001", "summary": "Bypass"}` + "\n" + `{"id": "CVE-2023-1002"}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []FeedItem{{ID: "CVE-2023-1001", Summary: "Bypass"}, {ID: "CVE-2023-1002"}}, items)
+	items, err = JSONFeedParser{}.Parse([]byte(`{"id": "CVE-2023-1001"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []FeedItem{{ID: "CVE-2023-1001"}}, items)
+	_, err = JSONFeedParser{}.Parse([]byte(`{"id": "CVE-2023-1001"}` + "\n" + `"CVE-2023-1002"`))
+	assert.ErrorIs(t, err, ErrDecode)
+	_, err = JSONFeedParser{}.Parse([]byte(`{"id": "CVE-2023-1001"}` + "\n" + `{"id": `))
+	assert.ErrorIs(t, err, ErrDecode)
+}
+
+func TestParseVulnDBDataNDJSON(t *testing.T) {
+	ts := newMitreServer(t)
+	feed, err := os.ReadFile("./testdata/feed/min-year.json")
+	assert.NoError(t, err)
+	want, err := ParseVulnDBData(feed, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+
+	ndjson, err := os.ReadFile("./testdata/feed/min-year.ndjson")
+	assert.NoError(t, err)
+	kvd, err := ParseVulnDBData(ndjson, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(kvd.Cves))
+	assert.Equal(t, want, kvd)
+}
@@ -0,0 +1,152 @@
+package cve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// fetch get url content, reading at most maxResponseSize bytes of the response body.
+// transient failures (network errors, 429 and 5xx responses) are retried with a jittered exponential backoff
+func (c collector) fetch(ctx context.Context, url string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, wrapError(ErrUpstream, ctx.Err())
+			case <-time.After(c.backoff.delay(attempt - 1)):
+			}
+		}
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return nil, wrapError(ErrUpstream, err)
+		}
+		body, retry, err := c.fetchOnce(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil || (attempt < c.retries && !c.spendRetry()) {
+			return nil, wrapError(ErrUpstream, err)
+		}
+		lastErr = err
+	}
+	return nil, wrapError(ErrUpstream, lastErr)
+}
+
+// fetchOnce get url content and report whether a failure is worth retrying
+func (c collector) fetchOnce(ctx context.Context, url string) ([]byte, bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, true, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode == http.StatusTooManyRequests {
+		c.limiter.throttled()
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+		return nil, retry, fmt.Errorf("unexpected status %d from %s", response.StatusCode, url)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, c.maxResponseSize+1))
+	if err != nil {
+		return nil, true, err
+	}
+	if int64(len(body)) > c.maxResponseSize {
+		return nil, false, fmt.Errorf("response body of %s exceeds max size of %d bytes", url, c.maxResponseSize)
+	}
+	c.limiter.succeeded()
+	return body, false, nil
+}
+
+// spendRetry take a retry from the collection retry budget, false once it is spent
+func (c collector) spendRetry() bool {
+	if c.retriesLeft == nil {
+		return true
+	}
+	return atomic.AddInt32(c.retriesLeft, -1) >= 0
+}
+
+// rateLimiter space requests at least interval apart, a nil limiter never wait
+type rateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+	// now and sleep are the wall clock, replaced by a fake one in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, until time.Time) error
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval, now: time.Now, sleep: sleepUntil}
+}
+
+// wait until the next request slot, or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	return l.sleep(ctx, at)
+}
+
+// sleepUntil wait until the until time, or ctx is done
+func sleepUntil(ctx context.Context, until time.Time) error {
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// backoff compute exponential retry delays with equal jitter
+type backoff struct {
+	base time.Duration
+	max  time.Duration
+	mu   sync.Mutex
+	rnd  *rand.Rand
+}
+
+func newBackoff(base, max time.Duration, src rand.Source) *backoff {
+	return &backoff{base: base, max: max, rnd: rand.New(src)}
+}
+
+// delay return the wait before retry number attempt (zero based): half of the capped exponential
+// delay plus a random share of the other half
+func (b *backoff) delay(attempt int) time.Duration {
+	d := b.base << uint(attempt)
+	if d <= 0 || d > b.max {
+		d = b.max
+	}
+	half := int64(d / 2)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(half + b.rnd.Int63n(half+1))
+}
@@ -0,0 +1,135 @@
+package cve
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 32)))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		hasErr  bool
+	}{
+		{name: "body within limit", maxSize: 32, hasErr: false},
+		{name: "body over limit", maxSize: 16, hasErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(WithMaxResponseSize(tt.maxSize)).fetch(context.Background(), ts.URL)
+			if tt.hasErr {
+				assert.ErrorContains(t, err, "exceeds max size")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 32, len(got))
+		})
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		retries int
+		hasErr  bool
+	}{
+		{name: "no retries", retries: 0, hasErr: true},
+		{name: "retry until success", retries: 2, hasErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			got, err := newCollector(WithRetries(tt.retries), WithRetryBackoff(time.Millisecond, time.Millisecond)).fetch(context.Background(), ts.URL)
+			if tt.hasErr {
+				assert.ErrorContains(t, err, "unexpected status 503")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", string(got))
+		})
+	}
+}
+
+func TestBackoffDeterministicSeed(t *testing.T) {
+	sequence := func(seed int64) []time.Duration {
+		b := newBackoff(100*time.Millisecond, 2*time.Second, rand.NewSource(seed))
+		delays := make([]time.Duration, 0)
+		for attempt := 0; attempt < 8; attempt++ {
+			delays = append(delays, b.delay(attempt))
+		}
+		return delays
+	}
+	first := sequence(42)
+	assert.Equal(t, first, sequence(42))
+	assert.NotEqual(t, first, sequence(7))
+	for attempt, d := range first {
+		want := 100 * time.Millisecond << uint(attempt)
+		if want > 2*time.Second {
+			want = 2 * time.Second
+		}
+		assert.True(t, d >= want/2 && d <= want, "delay %s out of bounds for attempt %d", d, attempt)
+	}
+}
+
+func TestFetchTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	// the test server certificate double as client certificate, the server does not verify it
+	clientCert := ts.TLS.Certificates[0]
+
+	tests := []struct {
+		name    string
+		opts    []option
+		wantErr string
+	}{
+		{name: "system roots", wantErr: "certificate"},
+		{name: "custom ca without client certificate", opts: []option{WithRootCAs(pool)}, wantErr: "unexpected status 403"},
+		{name: "custom ca with client certificate", opts: []option{WithRootCAs(pool), WithClientCertificate(clientCert)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(tt.opts...).fetch(context.Background(), ts.URL)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", string(got))
+		})
+	}
+}
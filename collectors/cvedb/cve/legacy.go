@@ -0,0 +1,117 @@
+package cve
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LegacyV4Record is the cve json 4.0 record mitre keep for older cves, under containers.cna.x_legacyV4Record
+type LegacyV4Record struct {
+	Affects struct {
+		Vendor struct {
+			VendorData []struct {
+				VendorName string `json:"vendor_name"`
+				Product    struct {
+					ProductData []struct {
+						ProductName string `json:"product_name"`
+						Version     struct {
+							VersionData []LegacyVersion `json:"version_data"`
+						}
+					} `json:"product_data"`
+				}
+			} `json:"vendor_data"`
+		}
+	}
+	Description struct {
+		DescriptionData []Descriptions `json:"description_data"`
+	}
+	Impact struct {
+		Cvss LegacyCvss
+	}
+	References struct {
+		ReferenceData []Reference `json:"reference_data"`
+	}
+}
+
+// LegacyCvss is the legacy record cvss, written either as an object or as a list of them
+type LegacyCvss struct {
+	VectorString string
+	BaseScore    float64
+	Version      string
+}
+
+// UnmarshalJSON decode a cvss object or the first cvss 3+ entry of a list
+func (lc *LegacyCvss) UnmarshalJSON(data []byte) error {
+	type plain LegacyCvss
+	var list []plain
+	if err := json.Unmarshal(data, &list); err != nil {
+		return json.Unmarshal(data, (*plain)(lc))
+	}
+	for _, c := range list {
+		if strings.HasPrefix(c.VectorString, "CVSS:") {
+			*lc = LegacyCvss(c)
+			return nil
+		}
+	}
+	return nil
+}
+
+type LegacyVersion struct {
+	VersionName     string `json:"version_name"`
+	VersionAffected string `json:"version_affected"`
+	VersionValue    string `json:"version_value"`
+}
+
+// fillCna fill the missing cna affected, descriptions, references and metrics from the legacy record
+// so it is parsed like a cve json 5.0 record
+func (r *LegacyV4Record) fillCna(containers *Containers) {
+	cna := &containers.Cna
+	for _, vendor := range r.Affects.Vendor.VendorData {
+		for _, product := range vendor.Product.ProductData {
+			affected := MitreAffected{Product: product.ProductName, Vendor: vendor.VendorName}
+			for _, lv := range product.Version.VersionData {
+				affected.Versions = append(affected.Versions, lv.mitreVersion())
+			}
+			cna.Affected = append(cna.Affected, affected)
+		}
+	}
+	if len(cna.Descriptions) == 0 {
+		for _, d := range r.Description.DescriptionData {
+			// legacy records use iso 639-2 language codes
+			if d.Lang == "eng" {
+				d.Lang = "en"
+			}
+			cna.Descriptions = append(cna.Descriptions, d)
+		}
+	}
+	if len(cna.References) == 0 {
+		cna.References = r.References.ReferenceData
+	}
+	if len(cna.Metrics) == 0 && len(r.Impact.Cvss.VectorString) > 0 {
+		var metric MitreMetric
+		switch {
+		case strings.HasPrefix(r.Impact.Cvss.VectorString, "CVSS:3.0"):
+			metric.CvssV3_0.VectorString = r.Impact.Cvss.VectorString
+		case strings.HasPrefix(r.Impact.Cvss.VectorString, "CVSS:3"):
+			metric.CvssV3_1.VectorString = r.Impact.Cvss.VectorString
+		case strings.HasPrefix(r.Impact.Cvss.VectorString, "CVSS:4"):
+			metric.CvssV4_0.VectorString, metric.CvssV4_0.BaseScore = r.Impact.Cvss.VectorString, r.Impact.Cvss.BaseScore
+		}
+		cna.Metrics = append(cna.Metrics, metric)
+	}
+}
+
+// mitreVersion convert a legacy version, version_affected is the operator applied to version_value
+// and version_name the release line it belongs to
+func (lv LegacyVersion) mitreVersion() *MitreVersion {
+	switch strings.TrimSpace(lv.VersionAffected) {
+	case "<":
+		return &MitreVersion{Status: "affected", Version: lv.VersionName, LessThan: lv.VersionValue}
+	case "<=":
+		return &MitreVersion{Status: "affected", Version: lv.VersionName, LessThanOrEqual: lv.VersionValue}
+	case "=", "":
+		return &MitreVersion{Status: "affected", Version: lv.VersionValue}
+	}
+	// operators such as >= or !< have no range equivalent and are skipped
+	return &MitreVersion{Status: "unsupported", Version: lv.VersionValue}
+}
@@ -0,0 +1,29 @@
+package cve
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyCvssUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want LegacyCvss
+	}{
+		{name: "object", data: `{"vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8, "version": "3.1"}`,
+			want: LegacyCvss{VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", BaseScore: 9.8, Version: "3.1"}},
+		{name: "list skipping v2", data: `[{"vectorString": "AV:N/AC:L/Au:N/C:P/I:P/A:P", "version": "2.0"}, {"vectorString": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "version": "3.0"}]`,
+			want: LegacyCvss{VectorString: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Version: "3.0"}},
+		{name: "empty list", data: `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LegacyCvss
+			assert.NoError(t, json.Unmarshal([]byte(tt.data), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
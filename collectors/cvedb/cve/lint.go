@@ -0,0 +1,128 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+// boilerplateSummaries match generic summaries usually left by a feed entry not parsed as expected
+var boilerplateSummaries = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(a )?(security )?(issue|vulnerability)( was)?( discovered)?( in kubernetes)?\.?$`),
+	regexp.MustCompile(`(?i)^(kubernetes )?security (issue|advisory|vulnerability)\.?$`),
+	regexp.MustCompile(`(?i)^(tbd|todo|n/a|none|placeholder)\.?$`),
+	regexp.MustCompile(`(?i)^cve-\d{4}-\d+$`),
+}
+
+// WithLint log LintCveData warnings of collected cves after validation
+func WithLint() option {
+	return func(o *options) {
+		o.lint = true
+	}
+}
+
+// LintCveData return warnings on cves passing validation yet looking badly parsed, such as a summary
+// identical to the description or a boilerplate summary
+func LintCveData(cves []*Vulnerability) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	for _, cve := range cves {
+		summary := strings.TrimSpace(cve.Summary)
+		if len(summary) == 0 {
+			continue
+		}
+		if strings.EqualFold(summary, strings.TrimSpace(cve.Description)) {
+			issues = append(issues, ValidationIssue{CveID: cve.ID, Code: IssueSummaryIsDescription, Message: "Summary is identical to Description", Warning: true})
+			continue
+		}
+		for _, re := range boilerplateSummaries {
+			if re.MatchString(summary) {
+				issues = append(issues, ValidationIssue{CveID: cve.ID, Code: IssueBoilerplateSummary, Message: fmt.Sprintf("Summary %q is boilerplate", summary), Warning: true})
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// WithDuplicateRangesLint log LintDuplicateRanges warnings of collected cves after validation
+func WithDuplicateRangesLint() option {
+	return func(o *options) {
+		o.duplicateRanges = true
+	}
+}
+
+// LintDuplicateRanges return a warning on each cve sharing the exact same ranges with another cve of its component,
+// unrelated cves rarely do so it usually point to a parsing fallback firing on both
+func LintDuplicateRanges(cves []*Vulnerability) []ValidationIssue {
+	groups := make(map[string][]string)
+	keys := make([]string, 0)
+	for _, cve := range cves {
+		ranges := make([]*Range, 0)
+		for _, a := range cve.Affected {
+			ranges = append(ranges, a.Ranges...)
+		}
+		if len(ranges) == 0 {
+			continue
+		}
+		data, err := json.Marshal(ranges)
+		if err != nil {
+			continue
+		}
+		key := cve.Component + "\x00" + string(data)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], cve.ID)
+	}
+	issues := make([]ValidationIssue, 0)
+	for _, key := range keys {
+		ids := groups[key]
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			others := make([]string, 0, len(ids)-1)
+			for _, other := range ids {
+				if other != id {
+					others = append(others, other)
+				}
+			}
+			issues = append(issues, ValidationIssue{CveID: id, Code: IssueDuplicateRanges,
+				Message: fmt.Sprintf("Ranges are identical to cve %s", strings.Join(others, ", ")), Warning: true})
+		}
+	}
+	return issues
+}
+
+// WithMaxComponents log LintComponentCount warnings of collected cves naming more than n components
+func WithMaxComponents(n int) option {
+	return func(o *options) {
+		o.maxComponents = n
+	}
+}
+
+// LintComponentCount return a warning on each cve whose summary and description name more than n distinct
+// components, a cve rarely affect that many components so it usually mean the description based detection
+// over-matched
+func LintComponentCount(cves []*Vulnerability, n int) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	for _, cve := range cves {
+		candidates := utils.ComponentCandidates(cve.Summary, cve.Description)
+		if len(candidates) <= n {
+			continue
+		}
+		names := make([]string, 0, len(candidates))
+		for name := range candidates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		issues = append(issues, ValidationIssue{CveID: cve.ID, Code: IssueTooManyComponents,
+			Message: fmt.Sprintf("Description names %d components (%s), review the %s component", len(names), strings.Join(names, ", "), cve.Component), Warning: true})
+	}
+	return issues
+}
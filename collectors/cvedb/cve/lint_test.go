@@ -0,0 +1,70 @@
+package cve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintCveData(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []ValidationIssue
+	}{
+		{name: "distinct summary", ids: []string{"CVE-2023-1001"}, want: []ValidationIssue{}},
+		{name: "summary identical to description", ids: []string{"CVE-2023-1001", "CVE-2023-1002"},
+			want: []ValidationIssue{{CveID: "CVE-2023-1002", Code: IssueSummaryIsDescription, Message: "Summary is identical to Description", Warning: true}}},
+		{name: "boilerplate summary", ids: []string{"CVE-2023-1003"},
+			want: []ValidationIssue{{CveID: "CVE-2023-1003", Code: IssueBoilerplateSummary, Message: `Summary "A security issue was discovered in Kubernetes." is boilerplate`, Warning: true}}},
+		{name: "summary is cve id", ids: []string{"CVE-2023-1004"},
+			want: []ValidationIssue{{CveID: "CVE-2023-1004", Code: IssueBoilerplateSummary, Message: `Summary "CVE-2023-1004" is boilerplate`, Warning: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cves := loadCves(t, "./testdata/vulndb/lint.json", tt.ids...)
+			got := LintCveData(cves)
+			assert.Equal(t, tt.want, got)
+			// lint findings never fail validation
+			assert.NoError(t, ValidateCveData(cves))
+		})
+	}
+}
+
+func TestLintDuplicateRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want []ValidationIssue
+	}{
+		{name: "distinct ranges", ids: []string{"CVE-2023-1001", "CVE-2023-1006"}, want: []ValidationIssue{}},
+		{name: "same ranges on other components", ids: []string{"CVE-2023-1001", "CVE-2023-1007"}, want: []ValidationIssue{}},
+		{name: "identical ranges on same component", ids: []string{"CVE-2023-1008", "CVE-2023-1006", "CVE-2023-1001"}, want: []ValidationIssue{
+			{CveID: "CVE-2023-1001", Code: IssueDuplicateRanges, Message: "Ranges are identical to cve CVE-2023-1008", Warning: true},
+			{CveID: "CVE-2023-1008", Code: IssueDuplicateRanges, Message: "Ranges are identical to cve CVE-2023-1001", Warning: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LintDuplicateRanges(loadCves(t, "./testdata/vulndb/lint.json", tt.ids...)))
+		})
+	}
+}
+
+func TestLintComponentCount(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		want []ValidationIssue
+	}{
+		{name: "within max", max: 4, want: []ValidationIssue{}},
+		{name: "over max", max: 2, want: []ValidationIssue{{CveID: "CVE-2023-1005", Code: IssueTooManyComponents,
+			Message: "Description names 4 components (apiserver, controller-manager, kube-proxy, kubelet), review the k8s.io/kubelet component", Warning: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cves := loadCves(t, "./testdata/vulndb/lint.json", "CVE-2023-1001", "CVE-2023-1005")
+			assert.Equal(t, tt.want, LintComponentCount(cves, tt.max))
+		})
+	}
+}
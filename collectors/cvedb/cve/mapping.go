@@ -0,0 +1,64 @@
+package cve
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// UnmappedComponent is a component named by feed cves that the component mapping tables
+// (see utils.UpstreamOrgByName) do not resolve to an upstream org
+type UnmappedComponent struct {
+	Component string
+	CveIDs    []string
+}
+
+// LintMapping collect the k8s vulndb feed cves and report the components failing to resolve to an upstream org
+func LintMapping(opts ...option) ([]UnmappedComponent, error) {
+	return LintMappingFromFeeds(context.Background(), []string{k8svulnDBURL}, opts...)
+}
+
+// LintMappingFromFeeds is LintMapping over several feeds, unmapped components are sorted by name
+func LintMappingFromFeeds(ctx context.Context, urls []string, opts ...option) ([]UnmappedComponent, error) {
+	c := newCollector(opts...)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	unmapped := make(map[string][]string)
+	var result error
+	for _, feedURL := range urls {
+		data, err := c.fetch(ctx, feedURL)
+		if err != nil {
+			return nil, err
+		}
+		items, err := c.feedParser.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		c.collectCves(ctx, feedJobs(items), func(r cveResult) {
+			if r.err != nil {
+				result = multierror.Append(result, r.err)
+				return
+			}
+			// getComponentName leave the org out of components it can not resolve
+			if r.vulnerability == nil || !strings.HasPrefix(r.vulnerability.Component, "/") {
+				return
+			}
+			component := strings.TrimPrefix(r.vulnerability.Component, "/")
+			unmapped[component] = append(unmapped[component], r.vulnerability.ID)
+		})
+	}
+	components := make([]UnmappedComponent, 0, len(unmapped))
+	for component, ids := range unmapped {
+		sort.Strings(ids)
+		components = append(components, UnmappedComponent{Component: component, CveIDs: ids})
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Component < components[j].Component
+	})
+	if ctx.Err() != nil {
+		result = multierror.Append(result, ctx.Err())
+	}
+	return components, result
+}
@@ -0,0 +1,34 @@
+package cve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintMappingFromFeeds(t *testing.T) {
+	mitre := newMitreServer(t)
+	feeds := http.NewServeMux()
+	feeds.HandleFunc("/unmapped.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/feed/unmapped.json")
+	})
+	feeds.HandleFunc("/components.json", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/feed/components.json")
+	})
+	ts := httptest.NewServer(feeds)
+	defer ts.Close()
+
+	got, err := LintMappingFromFeeds(context.Background(), []string{ts.URL + "/unmapped.json"}, WithMitreURL(mitre.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, []UnmappedComponent{{Component: "widget-operator", CveIDs: []string{"CVE-2023-1028"}}}, got)
+
+	got, err = LintMappingFromFeeds(context.Background(), []string{ts.URL + "/components.json"}, WithMitreURL(mitre.URL))
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = LintMappingFromFeeds(context.Background(), []string{ts.URL + "/missing.json"}, WithMitreURL(mitre.URL))
+	assert.ErrorIs(t, err, ErrUpstream)
+}
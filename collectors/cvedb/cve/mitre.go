@@ -1,14 +1,17 @@
 package cve
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"log"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-version"
 )
 
@@ -17,23 +20,97 @@ type MitreCVE struct {
 	Containers  Containers
 }
 
+// Containers hold the record providers data, the cna (the kubernetes cna for k8s cves) and the authorized
+// data publishers (adp, e.g. CISA-ADP) adding metrics or references to it
 type Containers struct {
-	Cna struct {
-		Affected []struct {
-			Product  string
-			Vendor   string
-			Versions []*MitreVersion
-		}
-		Descriptions []Descriptions
-		Metrics      []struct {
-			CvssV3_1 struct {
-				VectorString string
-			}
-			CvssV3_0 struct {
-				VectorString string
-			}
+	Cna MitreContainer
+	Adp []MitreContainer
+}
+
+// MitreContainer is the data of a single record provider
+type MitreContainer struct {
+	ProviderMetadata struct {
+		ShortName string
+	}
+	Affected     []MitreAffected
+	Descriptions []Descriptions
+	References   []Reference
+	Metrics      []MitreMetric
+	// LegacyV4Record is set on older records only available in the cve json 4.0 shape
+	LegacyV4Record *LegacyV4Record `json:"x_legacyV4Record"`
+}
+
+// mergeProviders fold the adp containers into the cna one. the cna is authoritative: adp affected versions,
+// descriptions and metrics are only used when the cna has none, taken from the first adp having some, while
+// adp references are appended after the cna ones
+func (c *Containers) mergeProviders() {
+	cna := &c.Cna
+	for _, adp := range c.Adp {
+		if len(cna.Affected) == 0 {
+			cna.Affected = adp.Affected
+		}
+		if len(cna.Descriptions) == 0 {
+			cna.Descriptions = adp.Descriptions
+		}
+		if !hasMetrics(cna.Metrics) {
+			cna.Metrics = adp.Metrics
+		}
+		cna.References = append(cna.References, adp.References...)
+	}
+}
+
+// hasMetrics check if any metric carry a cvss vector
+func hasMetrics(metrics []MitreMetric) bool {
+	for _, m := range metrics {
+		if len(m.CvssV3_1.VectorString) > 0 || len(m.CvssV3_0.VectorString) > 0 || len(m.CvssV4_0.VectorString) > 0 {
+			return true
 		}
 	}
+	return false
+}
+
+// MitreCvssV3 is a cvss v3 metric, BaseScore and BaseSeverity are usually given next to the vector
+type MitreCvssV3 struct {
+	VectorString string
+	BaseScore    float64
+	BaseSeverity string
+}
+
+// severity return the severity and score of the metric, the given base score and severity are authoritative
+// and preferred over the ones computed from the vector. the base severity is reported as the label of the
+// table band it names, a severity unknown to table (e.g. with custom bands) is mapped from the score instead
+func (m MitreCvssV3) severity(table utils.SeverityTable) (string, float64) {
+	if m.BaseScore <= 0 {
+		return utils.CvssVectorToSeverity(m.VectorString, table)
+	}
+	for _, b := range table {
+		if len(m.BaseSeverity) > 0 && strings.EqualFold(b.Label, m.BaseSeverity) {
+			return b.Label, m.BaseScore
+		}
+	}
+	return table.Severity(m.BaseScore), m.BaseScore
+}
+
+type MitreMetric struct {
+	CvssV3_1 MitreCvssV3
+	CvssV3_0 MitreCvssV3
+	CvssV4_0 struct {
+		VectorString string
+		BaseScore    float64
+	}
+}
+
+type MitreAffected struct {
+	Product      string
+	Vendor       string
+	Versions     []*MitreVersion
+	Modules      []string
+	ProgramFiles []string
+	// Repo is the source repository and CollectionURL the package repository, e.g. a github.com url
+	Repo          string
+	CollectionURL string `json:"collectionURL"`
+	// Tags flag the entry for triage, e.g. unsupported-when-assigned
+	Tags []string
 }
 
 type MitreVersion struct {
@@ -42,10 +119,40 @@ type MitreVersion struct {
 	LessThanOrEqual string
 	LessThan        string
 	VersionType     string
+	Changes         []MitreChange
+
+	// introduced is an explicit introduced bound parsed from a "from/since X prior to Y" version
+	// or a "X - Y" lessThanOrEqual range
+	introduced string
+}
+
+// customVersionType is the mitre version type of versions not following semver
+const customVersionType = "custom"
+
+var (
+	sincePriorToRegex = regexp.MustCompile(`(?i)^(?:from|since)\s+v?(\S+)\s+(?:and\s+)?prior to\s+v?(\S+)$`)
+	hyphenRangeRegex  = regexp.MustCompile(`^v?(\d+\.\d+(?:\.\d+)?)\s+-\s+v?(\d+\.\d+(?:\.\d+)?)$`)
+)
+
+// MitreChange is a status transition within a version range, e.g. back to unaffected once the fix landed
+type MitreChange struct {
+	At     string
+	Status string
 }
 
 type CveMetadata struct {
-	CveId string
+	CveId       string
+	State       string
+	DateUpdated string
+}
+
+// reservedState is the state of a cve record reserved but not yet published
+const reservedState = "RESERVED"
+
+type Reference struct {
+	Url  string
+	Name string
+	Tags []string
 }
 
 type Descriptions struct {
@@ -53,99 +160,448 @@ type Descriptions struct {
 	Value string
 }
 
-func parseMitreCve(externalURL string, cveID string) (*Vulnerability, error) {
+// parseMitreCve fetch and parse cve mitre record, recording each range derivation decision on trace when not nil
+func (c collector) parseMitreCve(ctx context.Context, externalURL string, cveID string, trace *DerivationTrace) (*Vulnerability, error) {
 
 	if strings.HasPrefix(externalURL, cveList) {
 		var cve MitreCVE
-		response, err := http.Get(fmt.Sprintf("%s/%s", mitreURL, cveID))
-		if err != nil {
-			return nil, err
-		}
-		cveInfo, err := io.ReadAll(response.Body)
+		cveInfo, err := c.mitreRecord(ctx, cveID)
 		if err != nil {
 			return nil, err
 		}
+		start := time.Now()
+		defer func() {
+			c.stats.addPhase(phaseParse, time.Since(start))
+		}()
 		err = json.Unmarshal(cveInfo, &cve)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(ErrDecode, fmt.Errorf("mitre record %s: %w", cveID, err))
+		}
+		if legacy := cve.Containers.Cna.LegacyV4Record; legacy != nil && len(cve.Containers.Cna.Affected) == 0 {
+			trace.record("no cna affected, fall back to legacy v4 record")
+			legacy.fillCna(&cve.Containers)
+		}
+		if len(cve.Containers.Adp) > 0 {
+			trace.record("merge %d adp providers into cna", len(cve.Containers.Adp))
+			cve.Containers.mergeProviders()
 		}
 		versions := make([]*Version, 0)
+		var possibleVersions []*Version
 		var component string
 		var requireMerge bool
+		preOne := c.preOneHandling && isPreOne(cve.Containers.Cna.Affected)
+		if preOne {
+			trace.record("all versions are pre-1.0, keep exact bounds and 0.x lines apart")
+		}
 		for _, a := range cve.Containers.Cna.Affected {
 			if len(component) == 0 {
-				component = a.Product
+				component = notApplicable(a.Product)
+			}
+			if len(component) == 0 || strings.ToLower(component) == "kubernetes" {
+				// product does not name the component, the repository url may
+				if repo := repoComponent(a); len(repo) > 0 {
+					trace.record("component %q from affected repository url", repo)
+					component = repo
+				}
 			}
 			for _, sv := range a.Versions {
-				if sv.Status == "affected" {
+				if sv.Status == "affected" && strings.EqualFold(sv.VersionType, customVersionType) {
+					trace.record("version %q lessThan %q lessThanOrEqual %q: custom version type, keep raw bounds", sv.Version, sv.LessThan, sv.LessThanOrEqual)
+					versions = append(versions, customVersion(sv, a))
+				} else if sv.Status == "affected" || (sv.Status == "unknown" && c.possiblyAffected) {
 					var from, to, fixed string
-					v, ok := sanitizedVersion(sv)
+					var openEnded bool
+					trace.record("version %q lessThan %q lessThanOrEqual %q: %s", sv.Version, sv.LessThan, sv.LessThanOrEqual, sv.Status)
+					raw := fmt.Sprintf("version %q lessThan %q lessThanOrEqual %q", sv.Version, sv.LessThan, sv.LessThanOrEqual)
+					// checked before sanitizing, which set both to the bound of a "prior to" version
+					onlyVersion := len(strings.TrimSpace(sv.LessThan)) > 0 && strings.TrimSpace(sv.LessThan) == strings.TrimSpace(sv.Version)
+					v, ok := sanitizedVersion(sv, trace)
 					if !ok {
 						continue
 					}
+					if c.strict && sv.Status == "affected" && !recognizedVersion(v) {
+						return nil, wrapError(ErrUnrecognizedVersion, fmt.Errorf("cve %s %s", cveID, raw))
+					}
+					if at := unaffectedAt(sv.Changes); len(at) > 0 {
+						trace.record("status transition: unaffected at %q, use as lessThan", at)
+						v.LessThan, v.LessThanOrEqual = utils.TrimString(at, []string{"v", "V"}), ""
+					}
 					switch {
 					case len(strings.TrimSpace(v.LessThanOrEqual)) > 0:
 						from, to = utils.ExtractVersions(v.LessThanOrEqual, v.Version, "lessThenEqual")
+						if len(v.introduced) > 0 {
+							from = v.introduced
+						}
+						trace.record("lessThanOrEqual branch: introduced %q last_affected %q", from, to)
+					case onlyVersion && strings.TrimSpace(v.LessThan) == strings.TrimSpace(v.Version) && len(v.introduced) == 0:
+						// lessThan equal to version would make an empty range, only that version is affected
+						from = utils.TrimString(v.Version, []string{"v", "V"})
+						to = from
+						trace.record("lessThan equal to version branch: introduced and last_affected %q", from)
 					case len(strings.TrimSpace(v.LessThan)) > 0:
 						from, to = utils.ExtractVersions(v.LessThan, v.Version, "lessThen")
 						if strings.HasSuffix(v.LessThan, ".0") {
 							from = "0"
 						}
+						if preOne && strings.Count(v.Version, ".") == 2 {
+							from = v.Version
+						}
+						if len(v.introduced) > 0 {
+							from = v.introduced
+						}
 						fixed = v.LessThan
+						trace.record("lessThan branch: introduced %q fixed %q", from, fixed)
+					case trailingRange(v):
+						from, to, fixed = utils.ExtractRange("", v.Version, "")
+						openEnded = len(to) == 0 && len(fixed) == 0
+						trace.record("and earlier/later branch: introduced %q last_affected %q fixed %q", from, to, fixed)
 					default:
-						if strings.Count(v.Version, ".") == 1 {
-							requireMerge = true
+						if strings.Count(v.Version, ".") == 1 && !c.minorLines {
+							from = v.Version + ".0"
+							to = from
+							trace.record("two-segment version branch: exact version %q", from)
+						} else if strings.Count(v.Version, ".") == 1 {
+							// unknown lines are expanded on their own, never merged with affected ones
+							requireMerge = requireMerge || sv.Status == "affected"
 							from = v.Version
+							trace.record("two-segment version branch: line %q require merge", from)
 						} else {
-							from, to = utils.ExtractVersions("", v.Version, "")
+							from, to, fixed = utils.ExtractRange("", v.Version, "")
+							trace.record("single version branch: introduced %q last_affected %q fixed %q", from, to, fixed)
 						}
 					}
-					ver := &Version{Introduced: from, Fixed: fixed, LastAffected: to}
+					if len(from) == 0 && len(to) == 0 && len(fixed) == 0 {
+						// an all empty version would yield no events and be dropped without a trace
+						log.Printf("skip cve %s %s: no version bounds extracted", cveID, raw)
+						trace.record("no version bounds extracted, skipped")
+						continue
+					}
+					if c.gaFixed {
+						fixed = gaVersion(fixed, trace)
+					}
+					ver := &Version{Introduced: from, Fixed: fixed, LastAffected: to, OpenEnded: openEnded, DatabaseSpecific: affectedScope(a)}
+					if sv.Status == "unknown" {
+						possibleVersions = append(possibleVersions, ver)
+						continue
+					}
 					versions = append(versions, ver)
 
+				} else {
+					trace.record("version %q: skipped, status %q", sv.Version, sv.Status)
 				}
 			}
 		}
-		vulnerableVersions := versions
-		if requireMerge {
+		vulnerableVersions := anchorFixedLines(versions, trace)
+		switch {
+		case requireMerge && preOne:
+			vulnerableVersions = expandVersionLines(versions)
+			trace.record("expanded %d pre-1.0 versions into %d ranges", len(versions), len(vulnerableVersions))
+		case requireMerge:
 			vulnerableVersions = mergeVersionRange(versions)
+			trace.record("merged %d versions into %d ranges", len(versions), len(vulnerableVersions))
 		}
-		vector, severity, score := getMetrics(cve)
+		vector, severity, score, cvssVersion := getMetrics(cve, c.severityTable)
 		description := getDescription(cve.Containers.Cna.Descriptions)
-		if strings.ToLower(component) == "kubernetes" {
-			component = utils.GetComponentFromDescriptionAndffected(description)
+		if len(component) == 0 || strings.ToLower(component) == "kubernetes" {
+			component = c.textComponent(vector, description)
 		}
 		return &Vulnerability{
 			Component:        component,
 			Description:      description,
+			Urls:             getReferences(cve.Containers.Cna.References),
 			AffectedVersions: vulnerableVersions,
-			CvssV3: Cvssv3{
-				Vector: vector,
-				Score:  score,
-			},
-			Severity: severity,
+			CvssV3:           newCvssv3(vector, score),
+			CvssVersion:      cvssVersion,
+			Severity:         severity,
+			Reserved:         cve.CveMetadata.State == reservedState,
+			UpdatedAt:        cve.CveMetadata.DateUpdated,
+			PossiblyAffected: possiblyAffected(possibleVersions),
 		}, nil
 	}
-	return nil, fmt.Errorf("unsupported external url %s", externalURL)
+	return nil, fmt.Errorf("%w %s", ErrUnsupportedURL, externalURL)
+}
+
+// gaVersion return the release of a pre-release version (e.g. 1.25.0 for 1.25.0-beta.1), other versions
+// are unchanged
+func gaVersion(ver string, trace *DerivationTrace) string {
+	v, err := version.NewVersion(ver)
+	if err != nil || len(v.Prerelease()) == 0 {
+		return ver
+	}
+	segments := v.Segments()
+	ga := fmt.Sprintf("%d.%d.%d", segments[0], segments[1], segments[2])
+	trace.record("pre-release fixed version %q reported as its release %q", ver, ga)
+	return ga
+}
+
+// possiblyAffected return the ranges of unknown status versions, each two-segment line is its own range
+func possiblyAffected(versions []*Version) []*Affected {
+	if len(versions) == 0 {
+		return nil
+	}
+	return GetAffectedEvents(&Vulnerability{AffectedVersions: expandVersionLines(versions)})
+}
+
+// customVersion keep the raw bounds of a custom version type, ordered by the vendor own scheme
+func customVersion(sv *MitreVersion, a MitreAffected) *Version {
+	introduced := strings.TrimSpace(sv.Version)
+	if len(introduced) == 0 {
+		introduced = "0"
+	}
+	return &Version{
+		Introduced:       introduced,
+		Fixed:            strings.TrimSpace(sv.LessThan),
+		LastAffected:     strings.TrimSpace(sv.LessThanOrEqual),
+		RangeType:        ecosystem,
+		DatabaseSpecific: affectedScope(a),
+	}
+}
+
+// anchorFixedLines start each range introduced at 0 on the minor line of its fixed version, records listing
+// one fix per line (e.g. < 1.23.8, < 1.24.4 and < 1.25.1) would otherwise overlap and cover fixed releases.
+// the range fixed on the lowest line keep its 0 bound as earlier lines are affected as well
+func anchorFixedLines(versions []*Version, trace *DerivationTrace) []*Version {
+	var lowest *minorLine
+	lines := make(map[*Version]*minorLine)
+	for _, v := range versions {
+		if len(v.RangeType) > 0 || normalizeZeroVersion(v.Introduced) != "0" {
+			continue
+		}
+		l := lineOf(v.Fixed, true)
+		if l == nil {
+			continue
+		}
+		lines[v] = l
+		if lowest == nil || l.major < lowest.major || (l.major == lowest.major && l.minor < lowest.minor) {
+			lowest = l
+		}
+	}
+	for _, v := range versions {
+		l, ok := lines[v]
+		if !ok || *l == *lowest {
+			continue
+		}
+		v.Introduced = fmt.Sprintf("%d.%d.0", l.major, l.minor)
+		trace.record("fixed %q anchor its range on line %d.%d", v.Fixed, l.major, l.minor)
+	}
+	return versions
+}
+
+// trailingRange check if a sanitized version is a "1.24.0 and earlier" or "1.24.0 and later" worded range
+func trailingRange(v *MitreVersion) bool {
+	_, _, _, ok := utils.TrailingRange(v.Version)
+	return ok && len(v.LessThan) == 0 && len(v.LessThanOrEqual) == 0
+}
+
+// recognizedVersion check every bound of a sanitized version is a parsable version
+func recognizedVersion(v *MitreVersion) bool {
+	if _, _, _, ok := utils.ThroughRange(v.Version); ok && len(v.LessThan) == 0 && len(v.LessThanOrEqual) == 0 {
+		return true
+	}
+	if trailingRange(v) {
+		return true
+	}
+	for _, bound := range []string{v.Version, v.LessThan, v.LessThanOrEqual} {
+		if len(bound) == 0 {
+			continue
+		}
+		if _, err := version.NewVersion(bound); err != nil {
+			return false
+		}
+	}
+	return len(v.Version) > 0 || len(v.LessThan) > 0 || len(v.LessThanOrEqual) > 0
+}
+
+// unaffectedAt return the version of the first transition back to unaffected, where the fix landed
+func unaffectedAt(changes []MitreChange) string {
+	for _, ch := range changes {
+		if ch.Status == "unaffected" && len(strings.TrimSpace(ch.At)) > 0 {
+			return strings.TrimSpace(ch.At)
+		}
+	}
+	return ""
+}
+
+// isPreOne check if every affected version of the record is a 0.x version
+func isPreOne(affected []MitreAffected) bool {
+	var found bool
+	for _, a := range affected {
+		for _, sv := range a.Versions {
+			if sv.Status != "affected" || strings.EqualFold(sv.VersionType, customVersionType) {
+				continue
+			}
+			for _, v := range []string{sv.Version, sv.LessThan, sv.LessThanOrEqual} {
+				v = utils.TrimString(v, []string{"v", "V", "<=", "<", "*"})
+				if len(v) == 0 || normalizeZeroVersion(v) == "0" || v == "unspecified" || v == "n/a" {
+					continue
+				}
+				if !strings.HasPrefix(v, "0.") {
+					return false
+				}
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// expandVersionLines turn each two-segment line into its own range, pre-1.0 minor lines are
+// independent release lines so consecutive lines are never merged into a wider range
+func expandVersionLines(affectedVersions []*Version) []*Version {
+	expanded := make([]*Version, 0)
+	sort.Sort(byVersion(affectedVersions))
+	for _, av := range affectedVersions {
+		if strings.Count(av.Introduced, ".") != 1 {
+			expanded = append(expanded, av)
+			continue
+		}
+		line, err := version.NewVersion(av.Introduced)
+		if err != nil {
+			continue
+		}
+		expanded = append(expanded, &Version{Introduced: av.Introduced + ".0", Fixed: nextLine(line), DatabaseSpecific: av.DatabaseSpecific})
+	}
+	return expanded
+}
+
+// affectedScope return the modules and program files the affected entry is scoped to and its tags, nil when
+// it has none
+func affectedScope(a MitreAffected) map[string]interface{} {
+	if len(a.Modules) == 0 && len(a.ProgramFiles) == 0 && len(a.Tags) == 0 {
+		return nil
+	}
+	scope := make(map[string]interface{})
+	if len(a.Modules) > 0 {
+		scope["modules"] = a.Modules
+	}
+	if len(a.ProgramFiles) > 0 {
+		scope["programFiles"] = a.ProgramFiles
+	}
+	if len(a.Tags) > 0 {
+		scope["tags"] = a.Tags
+	}
+	return scope
+}
+
+// githubRepoRegex match the org and repo of a github url, e.g. https://github.com/kubernetes/ingress-nginx.git
+var githubRepoRegex = regexp.MustCompile(`(?i)^(?:https?://)?(?:www\.)?github\.com/([\w.-]+)/([\w.-]+?)(?:\.git)?/?$`)
+
+// repoComponent return the org/repo of the affected repo or collectionURL github url, empty for other urls and
+// for the kubernetes/kubernetes monorepo whose component the description name better
+func repoComponent(a MitreAffected) string {
+	for _, u := range []string{a.Repo, a.CollectionURL} {
+		m := githubRepoRegex.FindStringSubmatch(strings.TrimSpace(u))
+		if m == nil {
+			continue
+		}
+		repo := strings.ToLower(m[1] + "/" + m[2])
+		if repo == "kubernetes/kubernetes" {
+			return ""
+		}
+		return repo
+	}
+	return ""
+}
+
+// notApplicable return empty value for mitre "n/a" placeholder used when product or vendor is unknown
+func notApplicable(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), "n/a") {
+		return ""
+	}
+	return value
 }
 
-func sanitizedVersion(v *MitreVersion) (*MitreVersion, bool) {
+// mitreRecord read cve record from each source in priority order, falling back to the next one on failure
+func (c collector) mitreRecord(ctx context.Context, cveID string) ([]byte, error) {
+	sources := c.recordSources()
+	var result error
+	for _, source := range sources {
+		start := time.Now()
+		record, err := c.recordFrom(ctx, source, cveID)
+		c.stats.recordFetch(cveID, time.Since(start), c.slowestFetches)
+		if err == nil {
+			return record, nil
+		}
+		if len(sources) == 1 {
+			return nil, err
+		}
+		result = multierror.Append(result, fmt.Errorf("%s source: %w", source, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, result
+}
+
+// recordSources return the configured source priority, by default the cve list when set and the mitre api otherwise
+func (c collector) recordSources() []string {
+	if len(c.sourcePriority) == 0 {
+		if c.cveList != nil {
+			return []string{SourceCVEList}
+		}
+		return []string{SourceAPI}
+	}
+	sources := make([]string, 0, len(c.sourcePriority))
+	for _, source := range c.sourcePriority {
+		if source == SourceCVEList && c.cveList == nil {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+func (c collector) recordFrom(ctx context.Context, source string, cveID string) ([]byte, error) {
+	switch source {
+	case SourceAPI:
+		return c.fetch(ctx, fmt.Sprintf("%s/%s", c.mitreURL, cveID))
+	case SourceCVEList:
+		return c.readCVEList(cveID)
+	}
+	return nil, fmt.Errorf("unknown mitre record source %q", source)
+}
+
+// trailingPunctuation is trimmed from the end of mitre versions and bounds, e.g. "1.24.2," or "1.24.2 "
+const trailingPunctuation = " \t\r\n,;"
+
+func sanitizedVersion(v *MitreVersion, trace *DerivationTrace) (*MitreVersion, bool) {
+	for _, field := range []*string{&v.Version, &v.LessThan, &v.LessThanOrEqual} {
+		if trimmed := strings.TrimRight(*field, trailingPunctuation); trimmed != *field {
+			trace.record("sanitize: trailing punctuation or whitespace trimmed from %q", *field)
+			*field = trimmed
+		}
+	}
 	if strings.Contains(v.Version, "n/a") && len(v.LessThan) == 0 && len(v.LessThanOrEqual) == 0 {
+		trace.record("sanitize: n/a version without bounds, skipped")
 		return v, false
 	}
 	if (v.LessThanOrEqual == "unspecified" || v.LessThan == "unspecified") && len(v.Version) > 0 {
+		trace.record("sanitize: unspecified bound, skipped")
 		return v, false
 	}
 	if v.LessThanOrEqual == "<=" {
+		trace.record("sanitize: bare <= bound, use version as lessThanOrEqual")
 		v.LessThanOrEqual = v.Version
 	}
 	if strings.HasPrefix(v.Version, "< ") {
+		trace.record("sanitize: < prefixed version, use as lessThan")
 		v.LessThan = strings.TrimPrefix(v.Version, "< ")
 	}
 	if strings.HasPrefix(v.Version, "<= ") {
+		trace.record("sanitize: <= prefixed version, use as lessThanOrEqual")
 		v.LessThanOrEqual = strings.TrimPrefix(v.Version, "<= ")
 	}
+	var introduced string
+	if m := sincePriorToRegex.FindStringSubmatch(strings.TrimSpace(v.Version)); m != nil {
+		trace.record("sanitize: from/since %q prior to version, use as introduced", m[1])
+		introduced = m[1]
+		if strings.Count(introduced, ".") == 1 {
+			introduced = introduced + ".0"
+		}
+		v.Version = "prior to " + m[2]
+	}
 	if strings.HasPrefix(strings.TrimSpace(v.Version), "prior to") {
+		trace.record("sanitize: prior to version, use as lessThan")
 		priorToVersion := strings.TrimSpace(strings.TrimPrefix(v.Version, "prior to"))
 		if strings.Count(priorToVersion, ".") == 1 {
 			priorToVersion = priorToVersion + ".0"
@@ -157,30 +613,54 @@ func sanitizedVersion(v *MitreVersion) (*MitreVersion, bool) {
 		v.LessThan = strings.TrimSpace(strings.TrimPrefix(v.Version, "prior to"))
 	}
 	if strings.HasSuffix(strings.TrimSpace(v.LessThan), "*") {
+		trace.record("sanitize: wildcard lessThan, use as version")
 		v.Version = strings.TrimSpace(strings.ReplaceAll(v.LessThan, "*", ""))
 		v.LessThan = ""
 	}
 	if strings.HasSuffix(strings.TrimSpace(v.Version), ".x") {
+		trace.record("sanitize: .x version, use as minor line")
 		v.Version = strings.TrimSpace(fmt.Sprintf("%s%s", v.Version[:strings.LastIndex(v.Version, ".")], ""))
 	}
 	if strings.Contains(v.LessThanOrEqual, "<=") {
 		v.LessThanOrEqual = strings.TrimSpace(strings.ReplaceAll(strings.TrimSpace(v.LessThanOrEqual), "<=", ""))
 	}
+	if m := hyphenRangeRegex.FindStringSubmatch(strings.TrimSpace(v.LessThanOrEqual)); m != nil {
+		trace.record("sanitize: lessThanOrEqual range %q, split into introduced and last affected", v.LessThanOrEqual)
+		introduced = m[1]
+		if strings.Count(introduced, ".") == 1 {
+			introduced = introduced + ".0"
+		}
+		v.Version, v.LessThanOrEqual = m[1], m[2]
+	}
 
 	return &MitreVersion{
 		Version:         utils.TrimString(v.Version, []string{"v", "V"}),
 		LessThanOrEqual: utils.TrimString(v.LessThanOrEqual, []string{"v", "V"}),
 		LessThan:        utils.TrimString(v.LessThan, []string{"v", "V"}),
+		introduced:      introduced,
 	}, true
 }
 
+// getDescription return the longest en description, records may have a short and a long one. among en
+// descriptions of the same length the first one is returned
 func getDescription(descriptions []Descriptions) string {
+	var description string
 	for _, d := range descriptions {
-		if d.Lang == "en" {
-			return d.Value
+		if d.Lang == "en" && len(d.Value) > len(description) {
+			description = d.Value
 		}
 	}
-	return ""
+	return description
+}
+
+func getReferences(references []Reference) []string {
+	urls := make([]string, 0)
+	for _, r := range references {
+		if len(strings.TrimSpace(r.Url)) > 0 {
+			urls = append(urls, strings.TrimSpace(r.Url))
+		}
+	}
+	return urls
 }
 
 type byVersion []*Version
@@ -205,51 +685,87 @@ func (s byVersion) Less(i, j int) bool {
 	return v1.LessThan(v2)
 }
 
+// mergeVersionRange collapse runs of consecutive two-segment (major.minor) affected lines into single ranges.
+// the merge maintains the following invariant: explicit (three-segment) ranges are kept as is, and each run of
+// consecutive lines (e.g. 1.20, 1.21 or 1.28, 2.0) become one range introduced at the run first line ".0" release
+// and ending at the explicit range introduced on the line directly after it (last_affected) or, when no explicit
+// range follow on that line, fixed at the line following the run last line. lines with a gap between them never
+// end up in the same range.
 func mergeVersionRange(affectedVersions []*Version) []*Version {
-	// this special handling is made to handle to case of conceutive vulnable major versions
 	newAffectedVesion := make([]*Version, 0)
 	sort.Sort(byVersion(affectedVersions))
-	var startVersion, lastVersion string
+	var startVersion string
+	var startScope map[string]interface{}
+	var lastLine *version.Version
 	for _, av := range affectedVersions {
-		if len(startVersion) == 0 && strings.Count(av.Introduced, ".") == 1 {
-			startVersion = av.Introduced
+		if strings.Count(av.Introduced, ".") != 1 {
+			if len(startVersion) > 0 {
+				closed := &Version{Introduced: startVersion + ".0", Fixed: nextLine(lastLine), DatabaseSpecific: startScope}
+				if explicit, err := version.NewVersion(av.Introduced); err == nil && consecutiveLines(lastLine, explicit) {
+					closed = &Version{Introduced: startVersion + ".0", LastAffected: av.Introduced, DatabaseSpecific: startScope}
+				}
+				newAffectedVesion = append(newAffectedVesion, closed)
+				startVersion = ""
+			}
+			newAffectedVesion = append(newAffectedVesion, av)
 			continue
 		}
-		if strings.Count(av.Introduced, ".") > 1 && len(lastVersion) == 0 && len(startVersion) > 0 {
-			lastVersion = av.Introduced
-			newAffectedVesion = append(newAffectedVesion, &Version{Introduced: startVersion + ".0", LastAffected: lastVersion})
-			newAffectedVesion = append(newAffectedVesion, &Version{Introduced: av.Introduced, LastAffected: av.LastAffected, Fixed: av.Fixed})
-			startVersion = ""
+		line, err := version.NewVersion(av.Introduced)
+		if err != nil {
 			continue
 		}
-		if len(lastVersion) > 0 || len(startVersion) == 0 {
-			newAffectedVesion = append(newAffectedVesion, av)
-			lastVersion = ""
+		if len(startVersion) > 0 && !consecutiveLines(lastLine, line) {
+			newAffectedVesion = append(newAffectedVesion, &Version{Introduced: startVersion + ".0", Fixed: nextLine(lastLine), DatabaseSpecific: startScope})
+			startVersion = ""
 		}
-	}
-
-	if lastVersion == "" && strings.Count(startVersion, ".") == 1 {
-		ver, err := version.NewSemver(affectedVersions[len(affectedVersions)-1].Introduced + ".0")
-		if err == nil {
-			versionParts := ver.Segments()
-			if len(versionParts) == 3 {
-				fixed := fmt.Sprintf("%d.%d.%d", versionParts[0], versionParts[1]+1, versionParts[2])
-				newAffectedVesion = append(newAffectedVesion, &Version{Introduced: startVersion + ".0", Fixed: fixed})
-			}
+		if len(startVersion) == 0 {
+			startVersion = av.Introduced
+			startScope = av.DatabaseSpecific
 		}
+		lastLine = line
+	}
+	if len(startVersion) > 0 {
+		newAffectedVesion = append(newAffectedVesion, &Version{Introduced: startVersion + ".0", Fixed: nextLine(lastLine), DatabaseSpecific: startScope})
 	}
 	return newAffectedVesion
 }
 
-func getMetrics(cve MitreCVE) (string, string, float64) {
-	var vectorString, severity string
+// consecutiveLines check if line directly follow prev, either next minor or first minor of next major
+func consecutiveLines(prev, line *version.Version) bool {
+	p, l := prev.Segments(), line.Segments()
+	switch {
+	case l[0] == p[0]:
+		return l[1] == p[1] || l[1] == p[1]+1
+	case l[0] == p[0]+1:
+		return l[1] == 0
+	}
+	return false
+}
+
+// nextLine return the first release of the minor line following line
+func nextLine(line *version.Version) string {
+	segments := line.Segments()
+	return fmt.Sprintf("%d.%d.0", segments[0], segments[1]+1)
+}
+
+// getMetrics return the record vector, severity, score and cvss version. v3 metrics are preferred and scored
+// from their base score and severity when given, v4.0 ones can not be decoded by go-cvss and are scored from
+// the record base score instead
+func getMetrics(cve MitreCVE, table utils.SeverityTable) (string, string, float64, string) {
+	var vectorString, severity, cvssVersion string
 	var score float64
 	for _, metric := range cve.Containers.Cna.Metrics {
-		vectorString = metric.CvssV3_0.VectorString
-		if len(vectorString) == 0 {
-			vectorString = metric.CvssV3_1.VectorString
+		switch {
+		case len(metric.CvssV3_0.VectorString) > 0:
+			vectorString, cvssVersion = metric.CvssV3_0.VectorString, "3.0"
+			severity, score = metric.CvssV3_0.severity(table)
+		case len(metric.CvssV3_1.VectorString) > 0:
+			vectorString, cvssVersion = metric.CvssV3_1.VectorString, "3.1"
+			severity, score = metric.CvssV3_1.severity(table)
+		case len(metric.CvssV4_0.VectorString) > 0 && !strings.HasPrefix(cvssVersion, "3"):
+			vectorString, cvssVersion, score = metric.CvssV4_0.VectorString, "4.0", metric.CvssV4_0.BaseScore
+			severity = table.Severity(score)
 		}
-		severity, score = utils.CvssVectorToScore(vectorString)
 	}
-	return vectorString, severity, score
+	return vectorString, severity, score, cvssVersion
 }
@@ -0,0 +1,465 @@
+package cve
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeVersionRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []*Version
+		want     []*Version
+	}{
+		{name: "consecutive minor lines", versions: []*Version{{Introduced: "1.21"}, {Introduced: "1.20"}, {Introduced: "1.22"}},
+			want: []*Version{{Introduced: "1.20.0", Fixed: "1.23.0"}}},
+		{name: "minor lines with gap", versions: []*Version{{Introduced: "1.20"}, {Introduced: "1.22"}},
+			want: []*Version{{Introduced: "1.20.0", Fixed: "1.21.0"}, {Introduced: "1.22.0", Fixed: "1.23.0"}}},
+		{name: "lines spanning major boundary", versions: []*Version{{Introduced: "1.28"}, {Introduced: "2.0"}, {Introduced: "2.1"}},
+			want: []*Version{{Introduced: "1.28.0", Fixed: "2.2.0"}}},
+		{name: "major jump not starting at minor zero", versions: []*Version{{Introduced: "1.28"}, {Introduced: "2.1"}},
+			want: []*Version{{Introduced: "1.28.0", Fixed: "1.29.0"}, {Introduced: "2.1.0", Fixed: "2.2.0"}}},
+		{name: "lines followed by explicit ranges", versions: []*Version{{Introduced: "1.1"}, {Introduced: "1.2"}, {Introduced: "1.3.0", Fixed: "1.3.5"}, {Introduced: "1.4.0", Fixed: "1.4.2"}},
+			want: []*Version{{Introduced: "1.1.0", LastAffected: "1.3.0"}, {Introduced: "1.3.0", Fixed: "1.3.5"}, {Introduced: "1.4.0", Fixed: "1.4.2"}}},
+		{name: "lines followed by explicit range after a gap", versions: []*Version{{Introduced: "1.20"}, {Introduced: "1.21"}, {Introduced: "1.24.0", Fixed: "1.24.3"}},
+			want: []*Version{{Introduced: "1.20.0", Fixed: "1.22.0"}, {Introduced: "1.24.0", Fixed: "1.24.3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeVersionRange(tt.versions)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseMitreCvePreOneVersions(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1006"
+	tests := []struct {
+		name string
+		opts []option
+		want []*Version
+	}{
+		{name: "pre-1.0 handling", opts: []option{WithMitreURL(ts.URL)},
+			want: []*Version{{Introduced: "0.7.0", Fixed: "0.8.0"}, {Introduced: "0.8.0", Fixed: "0.9.0"}, {Introduced: "0.9.1", Fixed: "0.9.4"}}},
+		{name: "1.x heuristics", opts: []option{WithMitreURL(ts.URL), WithPreOneHandling(false)},
+			want: []*Version{{Introduced: "0.7.0", LastAffected: "0.9.0"}, {Introduced: "0.9.0", Fixed: "0.9.4"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(tt.opts...).parseMitreCve(context.Background(), externalURL, "CVE-2023-1006", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.AffectedVersions)
+		})
+	}
+}
+
+func TestParseMitreCveStatusChanges(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1008"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1008", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Version{
+		{Introduced: "1.24.0", Fixed: "1.24.3"},
+		{Introduced: "1.25.0", Fixed: "1.25.5"},
+		{Introduced: "1.26.0", Fixed: "1.26.2"},
+	}, got.AffectedVersions)
+}
+
+func TestParseMitreCvePriorTo(t *testing.T) {
+	ts := newMitreServer(t)
+	tests := []struct {
+		name  string
+		cveID string
+		want  []*Version
+	}{
+		{name: "prior to", cveID: "CVE-2023-1010", want: []*Version{{Introduced: "1.24.0", Fixed: "1.24.2"}}},
+		{name: "from prior to", cveID: "CVE-2023-1011", want: []*Version{{Introduced: "1.23.0", Fixed: "1.24.2"}}},
+		{name: "lessThan trailing whitespace", cveID: "CVE-2023-1025", want: []*Version{{Introduced: "1.24.0", Fixed: "1.24.2"}}},
+		{name: "lessThan trailing comma", cveID: "CVE-2023-1026", want: []*Version{{Introduced: "1.24.0", Fixed: "1.24.2"}, {Introduced: "1.25.0", Fixed: "1.25.5"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + tt.cveID
+			got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, tt.cveID, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.AffectedVersions)
+		})
+	}
+}
+
+func TestParseMitreCveTwoSegmentVersions(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1027"
+	tests := []struct {
+		name string
+		opts []option
+		want []*Version
+	}{
+		{name: "minor lines", opts: []option{WithMitreURL(ts.URL)},
+			want: []*Version{{Introduced: "1.23.0", Fixed: "1.25.0"}}},
+		{name: "exact versions", opts: []option{WithMitreURL(ts.URL), WithMinorLines(false)},
+			want: []*Version{{Introduced: "1.23.0", LastAffected: "1.23.0"}, {Introduced: "1.24.0", LastAffected: "1.24.0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(tt.opts...).parseMitreCve(context.Background(), externalURL, "CVE-2023-1027", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.AffectedVersions)
+		})
+	}
+}
+
+func TestParseMitreCveProviders(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1029"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1029", nil)
+	assert.NoError(t, err)
+	// cna affected win over the adp ones, the cna has no metrics so the first adp metrics are used
+	assert.Equal(t, "kubelet", got.Component)
+	assert.Equal(t, []*Version{{Introduced: "1.24.0", Fixed: "1.24.2"}}, got.AffectedVersions)
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", got.CvssV3.Vector)
+	assert.Equal(t, "Medium", got.Severity)
+	assert.Equal(t, []string{
+		"https://github.com/kubernetes/kubernetes/issues/1029",
+		"https://groups.google.com/g/kubernetes-security-announce/c/1029",
+		"https://www.cve.org/CVERecord?id=CVE-2023-1029",
+	}, got.Urls)
+}
+
+func TestSanitizedVersionSincePriorTo(t *testing.T) {
+	got, ok := sanitizedVersion(&MitreVersion{Status: "affected", Version: "since v1.23 prior to v1.24.2"}, nil)
+	assert.True(t, ok)
+	assert.Equal(t, &MitreVersion{Version: "1.24.2", LessThan: "1.24.2", introduced: "1.23.0"}, got)
+}
+
+func TestParseMitreCveModules(t *testing.T) {
+	ts := newMitreServer(t)
+	scope := map[string]interface{}{
+		"modules":      []string{"kubelet/kuberuntime"},
+		"programFiles": []string{"pkg/kubelet/kuberuntime/kuberuntime_container.go"},
+	}
+	tests := []struct {
+		name  string
+		cveID string
+		want  []*Affected
+	}{
+		{name: "scoped to modules", cveID: "CVE-2023-1012", want: []*Affected{
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.9"}}}}, DatabaseSpecific: scope},
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.25.0"}, {Fixed: "1.25.4"}}}}, DatabaseSpecific: scope},
+		}},
+		{name: "tagged", cveID: "CVE-2023-1035", want: []*Affected{
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.20.0"}, {Fixed: "1.20.15"}}}},
+				DatabaseSpecific: map[string]interface{}{"tags": []string{"unsupported-when-assigned"}}},
+		}},
+		{name: "without modules or tags", cveID: "CVE-2023-1001", want: []*Affected{
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.2"}}}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + tt.cveID
+			got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, tt.cveID, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, GetAffectedEvents(got))
+		})
+	}
+}
+
+func TestParseMitreCveStrict(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/unrecognized-version.json")
+	assert.NoError(t, err)
+
+	// without strict mode the version is skipped, leaving the cve without versions to collect
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithPartialResults())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(kvd.Cves))
+
+	_, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithStrict())
+	assert.ErrorIs(t, err, ErrUnrecognizedVersion)
+	assert.ErrorContains(t, err, `cve CVE-2023-1013 version "all versions before the fix"`)
+
+	// known shapes keep parsing in strict mode
+	b, err = os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithStrict())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(kvd.Cves))
+}
+
+func TestParseMitreCvePrereleaseFixed(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1034"
+	tests := []struct {
+		name string
+		opts []option
+		want []*Version
+	}{
+		{name: "exact fixed version", opts: []option{WithMitreURL(ts.URL)},
+			want: []*Version{{Introduced: "0", Fixed: "1.25.0-beta.1"}}},
+		{name: "ga fixed version", opts: []option{WithMitreURL(ts.URL), WithGAFixedVersions()},
+			want: []*Version{{Introduced: "0", Fixed: "1.25.0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(tt.opts...).parseMitreCve(context.Background(), externalURL, "CVE-2023-1034", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.AffectedVersions)
+		})
+	}
+}
+
+func TestParseMitreCveLongestDescription(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1036"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1036", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "A security issue was discovered in kubelet that allows pods to bypass the seccomp profile enforcement "+
+		"when the seccomp profile is set through the deprecated annotations.", got.Description)
+}
+
+func TestParseMitreCveThroughRange(t *testing.T) {
+	ts := newMitreServer(t)
+	for _, cveID := range []string{"CVE-2023-1037", "CVE-2023-1038"} {
+		t.Run(cveID, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + cveID
+			got, err := newCollector(WithMitreURL(ts.URL), WithStrict()).parseMitreCve(context.Background(), externalURL, cveID, nil)
+			assert.NoError(t, err)
+			// the 1.22 upper bound cover the whole line
+			assert.Equal(t, []*Version{{Introduced: "1.20.0", Fixed: "1.23.0"}}, got.AffectedVersions)
+		})
+	}
+}
+
+func TestParseMitreCveTrailingRange(t *testing.T) {
+	ts := newMitreServer(t)
+	tests := []struct {
+		cveID string
+		want  []*Version
+		event []*Event
+	}{
+		{cveID: "CVE-2023-1043", want: []*Version{{Introduced: "0", LastAffected: "1.24.0"}},
+			event: []*Event{{Introduced: "0"}, {LastAffected: "1.24.0"}}},
+		{cveID: "CVE-2023-1044", want: []*Version{{Introduced: "1.24.0", OpenEnded: true}},
+			event: []*Event{{Introduced: "1.24.0"}}},
+		{cveID: "CVE-2023-1045", want: []*Version{{Introduced: "0", Fixed: "1.25.0"}},
+			event: []*Event{{Introduced: "0"}, {Fixed: "1.25.0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cveID, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + tt.cveID
+			got, err := newCollector(WithMitreURL(ts.URL), WithStrict()).parseMitreCve(context.Background(), externalURL, tt.cveID, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.AffectedVersions)
+			assert.Equal(t, []*Affected{{Ranges: []*Range{{RangeType: semver, Events: tt.event}}}}, GetAffectedEvents(got))
+		})
+	}
+}
+
+func TestParseMitreCveEmptyBounds(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1033"
+	trace := &DerivationTrace{CveID: "CVE-2023-1033"}
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1033", trace)
+	assert.NoError(t, err)
+	// the version no bound is extracted from is skipped instead of becoming an all empty version
+	assert.Equal(t, []*Version{{Introduced: "1.26.0", Fixed: "1.26.3"}}, got.AffectedVersions)
+	assert.Contains(t, trace.Steps, "no version bounds extracted, skipped")
+}
+
+func TestParseMitreCveLessThanOrEqualRange(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1014"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1014", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Version{
+		{Introduced: "1.20.0", LastAffected: "1.22.3"},
+		{Introduced: "1.23.0", LastAffected: "1.23.5"},
+	}, got.AffectedVersions)
+}
+
+func TestMitreCvssV3Severity(t *testing.T) {
+	vector := "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N"
+	custom := utils.SeverityTable{{MinScore: 0, Label: "Minor"}, {MinScore: 6.0, Label: "Major"}}
+	tests := []struct {
+		name         string
+		metric       MitreCvssV3
+		table        utils.SeverityTable
+		wantSeverity string
+		wantScore    float64
+	}{
+		{name: "vector only", metric: MitreCvssV3{VectorString: vector}, table: utils.DefaultSeverityTable, wantSeverity: "Medium", wantScore: 6.5},
+		{name: "base score and severity preferred", metric: MitreCvssV3{VectorString: vector, BaseScore: 7.1, BaseSeverity: "HIGH"},
+			table: utils.DefaultSeverityTable, wantSeverity: "High", wantScore: 7.1},
+		{name: "base score without severity", metric: MitreCvssV3{VectorString: vector, BaseScore: 7.1}, table: utils.DefaultSeverityTable,
+			wantSeverity: "High", wantScore: 7.1},
+		{name: "severity unknown to table", metric: MitreCvssV3{VectorString: vector, BaseScore: 6.5, BaseSeverity: "MEDIUM"}, table: custom,
+			wantSeverity: "Major", wantScore: 6.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, score := tt.metric.severity(tt.table)
+			assert.Equal(t, tt.wantSeverity, severity)
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func TestParseMitreCveCvssVersion(t *testing.T) {
+	ts := newMitreServer(t)
+	tests := []struct {
+		name         string
+		cveID        string
+		wantVersion  string
+		wantScore    float64
+		wantSeverity string
+	}{
+		{name: "v3.1", cveID: "CVE-2023-1001", wantVersion: "3.1", wantScore: 3.4, wantSeverity: "Low"},
+		{name: "v4.0", cveID: "CVE-2023-1016", wantVersion: "4.0", wantScore: 9.3, wantSeverity: "Critical"},
+		{name: "v3.1 base severity", cveID: "CVE-2023-1042", wantVersion: "3.1", wantScore: 6.5, wantSeverity: "Medium"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + tt.cveID
+			got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, tt.cveID, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, got.CvssVersion)
+			assert.Equal(t, tt.wantScore, got.CvssV3.Score)
+			assert.Equal(t, tt.wantSeverity, got.Severity)
+		})
+	}
+}
+
+func TestParseMitreCveLegacyV4Record(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1017"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1017", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Vulnerability{
+		Component:   "kubelet",
+		Description: "The kubelet allows a malicious container to escalate privileges through redirects of its log endpoint.",
+		Urls:        []string{"https://github.com/kubernetes/kubernetes/issues/1017"},
+		AffectedVersions: []*Version{
+			{Introduced: "1.17.0", Fixed: "1.17.9"},
+			{Introduced: "1.18.0", Fixed: "1.18.6"},
+		},
+		CvssV3:      newCvssv3("CVSS:3.1/AV:N/AC:H/PR:H/UI:N/S:C/C:H/I:H/A:N", 7.7),
+		CvssVersion: "3.1",
+		Severity:    "High",
+	}, got)
+}
+
+func TestParseMitreCveCustomVersionType(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1018"
+	got, err := newCollector(WithMitreURL(ts.URL), WithStrict()).parseMitreCve(context.Background(), externalURL, "CVE-2023-1018", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Version{
+		{Introduced: "release-2023-04", Fixed: "release-2023-09", RangeType: ecosystem},
+		{Introduced: "release-2022-11", LastAffected: "release-2022-12", RangeType: ecosystem},
+	}, got.AffectedVersions)
+
+	v := loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")[0]
+	v.ID = "CVE-2023-1018"
+	v.AffectedVersions = got.AffectedVersions
+	v.Affected = GetAffectedEvents(v)
+	assert.Equal(t, []*Affected{
+		{Ranges: []*Range{{RangeType: ecosystem, Events: []*Event{{Introduced: "release-2023-04"}, {Fixed: "release-2023-09"}}}}},
+		{Ranges: []*Range{{RangeType: ecosystem, Events: []*Event{{Introduced: "release-2022-11"}, {LastAffected: "release-2022-12"}}}}},
+	}, v.Affected)
+	assert.NoError(t, ValidateCveData([]*Vulnerability{v}))
+}
+
+func TestParseMitreCveLessThanEqualsVersion(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1020"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1020", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Version{{Introduced: "1.25.3", LastAffected: "1.25.3"}}, got.AffectedVersions)
+	assert.Equal(t, []*Affected{
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.25.3"}, {LastAffected: "1.25.3"}}}}},
+	}, GetAffectedEvents(got))
+}
+
+func TestParseMitreCveComponentFromCvss(t *testing.T) {
+	ts := newMitreServer(t)
+	tests := []struct {
+		name  string
+		cveID string
+		want  string
+	}{
+		// both records name the kubelet and the kube-apiserver once
+		{name: "local container escape", cveID: "CVE-2023-1021", want: "kubelet"},
+		{name: "remote authenticated user", cveID: "CVE-2023-1022", want: "apiserver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			externalURL := "https://www.cve.org/cverecord?id=" + tt.cveID
+			got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, tt.cveID, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Component)
+		})
+	}
+}
+
+func TestParseMitreCveFixedPerMinorLine(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1023"
+	got, err := newCollector(WithMitreURL(ts.URL)).parseMitreCve(context.Background(), externalURL, "CVE-2023-1023", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Affected{
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "0"}, {Fixed: "1.23.8"}}}}},
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.4"}}}}},
+		{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.25.0"}, {Fixed: "1.25.1"}}}}},
+	}, GetAffectedEvents(got))
+}
+
+func TestParseMitreCvePossiblyAffected(t *testing.T) {
+	ts := newMitreServer(t)
+	externalURL := "https://www.cve.org/cverecord?id=CVE-2023-1024"
+	affected := []*Version{{Introduced: "1.26.0", Fixed: "1.26.3"}}
+	tests := []struct {
+		name string
+		opts []option
+		want []*Affected
+	}{
+		{name: "unknown status skipped by default", opts: []option{WithMitreURL(ts.URL)}},
+		{name: "unknown status collected", opts: []option{WithMitreURL(ts.URL), WithPossiblyAffected()}, want: []*Affected{
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.9"}}}}},
+			{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.25.0"}, {Fixed: "1.26.0"}}}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCollector(tt.opts...).parseMitreCve(context.Background(), externalURL, "CVE-2023-1024", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, affected, got.AffectedVersions)
+			assert.Equal(t, tt.want, got.PossiblyAffected)
+		})
+	}
+}
+
+func TestRepoComponent(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected MitreAffected
+		want     string
+	}{
+		{name: "collection url", affected: MitreAffected{CollectionURL: "https://github.com/kubernetes/ingress-nginx"}, want: "kubernetes/ingress-nginx"},
+		{name: "repo preferred", affected: MitreAffected{Repo: "https://github.com/Kubernetes-SIGs/secrets-store-csi-driver.git", CollectionURL: "https://github.com/kubernetes/ingress-nginx"},
+			want: "kubernetes-sigs/secrets-store-csi-driver"},
+		{name: "monorepo", affected: MitreAffected{Repo: "https://github.com/kubernetes/kubernetes"}},
+		{name: "not github", affected: MitreAffected{CollectionURL: "https://registry.k8s.io"}},
+		{name: "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, repoComponent(tt.affected))
+		})
+	}
+}
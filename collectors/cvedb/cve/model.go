@@ -1,5 +1,9 @@
 package cve
 
+import (
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
 type Vulnerability struct {
 	ID               string      `json:"id,omitempty"`
 	CreatedAt        string      `json:"created_at,omitempty"`
@@ -10,7 +14,23 @@ type Vulnerability struct {
 	Affected         []*Affected `json:"affected,omitempty"`
 	Urls             []string    `json:"references,omitempty"`
 	CvssV3           Cvssv3      `json:"cvssv3,omitempty"`
+	CvssVersion      string      `json:"cvss_version,omitempty"`
 	Severity         string      `json:"severity,omitempty"`
+	Resources        []string    `json:"resources,omitempty"`
+	// AdvisoryRefs list the github issues, pull requests and KEPs referenced by the advisory
+	AdvisoryRefs []string `json:"advisory_refs,omitempty"`
+	// PossiblyAffected list the ranges of versions with an unknown status, collected with WithPossiblyAffected
+	PossiblyAffected []*Affected `json:"possibly_affected,omitempty"`
+	// Reserved is set on placeholders of cves reserved but not yet published
+	Reserved bool `json:"reserved,omitempty"`
+	// Tags is the exploitability tags of the CvssV3 vector, e.g. network-exploitable
+	Tags []string `json:"tags,omitempty"`
+	// UpdatedAt is the mitre record dateUpdated, used by WithChangedSince
+	UpdatedAt string `json:"-"`
+	// ComponentFallback is set when no component was detected and the WithFallbackComponent one is used
+	ComponentFallback bool `json:"component_fallback,omitempty"`
+
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
 }
 
 type K8sVulnDB struct {
@@ -20,6 +40,18 @@ type K8sVulnDB struct {
 type Cvssv3 struct {
 	Vector string
 	Score  float64
+	// Components is the parsed Vector base metrics
+	Components *utils.CvssComponents `json:",omitempty"`
+}
+
+// newCvssv3 return the cvss of vector and score, with components parsed from a non empty vector
+func newCvssv3(vector string, score float64) Cvssv3 {
+	cvss := Cvssv3{Vector: vector, Score: score}
+	if len(vector) > 0 {
+		components := utils.ParseCvssVector(vector)
+		cvss.Components = &components
+	}
+	return cvss
 }
 
 type Version struct {
@@ -28,10 +60,18 @@ type Version struct {
 	LastAffected string `json:"last_affected,omitempty"`
 	Limit        string `json:"limit,omitempty"`
 	FixedIndex   int    `json:"-"`
+	// RangeType is the range type of non semver versions, empty for semver
+	RangeType string `json:"-"`
+	// OpenEnded is set on versions affected from Introduced on without upper bound, e.g. "1.24.0 and later"
+	OpenEnded bool `json:"-"`
+
+	DatabaseSpecific map[string]interface{} `json:"-"`
 }
 
 type Affected struct {
 	Ranges []*Range `json:"ranges,omitempty"`
+
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
 }
 
 type Range struct {
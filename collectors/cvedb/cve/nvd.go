@@ -0,0 +1,184 @@
+package cve
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+// nvdKey is the database_specific key recording the fields enriched from nvd
+const nvdKey = "nvd"
+
+// NVDFeed is an nvd cve json 2.0 feed file (e.g. nvdcve-2.0-2023.json)
+type NVDFeed struct {
+	Vulnerabilities []struct {
+		Cve NVDCve `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// NVDCve is the subset of an nvd cve record used for enrichment
+type NVDCve struct {
+	ID      string `json:"id"`
+	Metrics struct {
+		CvssMetricV31 []NVDCvssMetric `json:"cvssMetricV31"`
+		CvssMetricV30 []NVDCvssMetric `json:"cvssMetricV30"`
+	} `json:"metrics"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+}
+
+// NVDCvssMetric is an nvd cvss v3 metric, Type is Primary for the nvd own assessment
+type NVDCvssMetric struct {
+	Type     string `json:"type"`
+	CvssData struct {
+		Version      string  `json:"version"`
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+	} `json:"cvssData"`
+}
+
+// NVDIndex map cve ids to their nvd record
+type NVDIndex map[string]NVDCve
+
+// LoadNVDFeed read nvd json 2.0 feed files, gzipped when ending with .gz, into an index by cve id.
+// a cve found in several files keep its record from the last one
+func LoadNVDFeed(paths ...string) (NVDIndex, error) {
+	index := make(NVDIndex)
+	for _, path := range paths {
+		feed, err := readNVDFeed(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range feed.Vulnerabilities {
+			index[v.Cve.ID] = v.Cve
+		}
+	}
+	return index, nil
+}
+
+func readNVDFeed(path string) (*NVDFeed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, wrapError(ErrDecode, fmt.Errorf("nvd feed %s: %w", path, err))
+		}
+		defer gz.Close()
+		r = gz
+	}
+	var feed NVDFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, wrapError(ErrDecode, fmt.Errorf("nvd feed %s: %w", path, err))
+	}
+	return &feed, nil
+}
+
+// WithNVD set an nvd index filling the cvss of cves lacking one on mitre and adding the nvd references,
+// applied before overrides
+func WithNVD(index NVDIndex) option {
+	return func(o *options) {
+		o.nvd = index
+	}
+}
+
+// cvss return the primary cvss v3 metric vector and version, v3.1 preferred over v3.0
+func (n NVDCve) cvss() (string, string) {
+	for _, metrics := range [][]NVDCvssMetric{n.Metrics.CvssMetricV31, n.Metrics.CvssMetricV30} {
+		var vector, ver string
+		for _, m := range metrics {
+			if len(m.CvssData.VectorString) == 0 {
+				continue
+			}
+			if len(vector) == 0 || m.Type == "Primary" {
+				vector, ver = m.CvssData.VectorString, m.CvssData.Version
+			}
+		}
+		if len(vector) > 0 {
+			return vector, ver
+		}
+	}
+	return "", ""
+}
+
+// WithNVDURL set an nvd cve api 2.0 url (e.g. https://services.nvd.nist.gov/rest/json/cves/2.0) cves missing
+// from the WithNVD index are looked up at, sharing the mitre fetch retries, rate limit and retry budget
+func WithNVDURL(url string) option {
+	return func(o *options) {
+		o.nvdURL = url
+	}
+}
+
+// lookupNVD return the nvd record of cveID from the index, or from the nvd api when set
+func (c collector) lookupNVD(ctx context.Context, cveID string) (NVDCve, bool) {
+	if n, ok := c.nvd[cveID]; ok || len(c.nvdURL) == 0 {
+		return n, ok
+	}
+	data, err := c.fetch(ctx, fmt.Sprintf("%s?cveId=%s", c.nvdURL, url.QueryEscape(cveID)))
+	if err != nil {
+		log.Printf("skip nvd enrichment of cve %s: %s", cveID, err)
+		return NVDCve{}, false
+	}
+	var feed NVDFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		log.Printf("skip nvd enrichment of cve %s: %s", cveID, wrapError(ErrDecode, err))
+		return NVDCve{}, false
+	}
+	for _, v := range feed.Vulnerabilities {
+		if v.Cve.ID == cveID {
+			return v.Cve, true
+		}
+	}
+	return NVDCve{}, false
+}
+
+// applyNVD enrich cves found in the nvd index or api and record the enriched fields in their database_specific,
+// nvd api failures only skip the enrichment of the cve
+func (c collector) applyNVD(ctx context.Context, cves []*Vulnerability) {
+	if len(c.nvd) == 0 && len(c.nvdURL) == 0 {
+		return
+	}
+	for _, cve := range cves {
+		n, ok := c.lookupNVD(ctx, cve.ID)
+		if !ok {
+			continue
+		}
+		fields := make([]string, 0)
+		if vector, ver := n.cvss(); len(cve.CvssV3.Vector) == 0 && len(vector) > 0 {
+			severity, score := utils.CvssVectorToSeverity(vector, c.severityTable)
+			cve.CvssV3 = newCvssv3(vector, score)
+			cve.CvssVersion = ver
+			cve.Severity = severity
+			fields = append(fields, "cvss")
+		}
+		urls := make([]string, 0, len(cve.Urls)+len(n.References))
+		urls = append(urls, cve.Urls...)
+		for _, r := range n.References {
+			urls = append(urls, r.URL)
+		}
+		if urls = dedupURLs(urls...); len(urls) > len(cve.Urls) {
+			cve.Urls = urls
+			fields = append(fields, "references")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if cve.DatabaseSpecific == nil {
+			cve.DatabaseSpecific = make(map[string]interface{})
+		}
+		cve.DatabaseSpecific[nvdKey] = map[string]interface{}{"fields": fields}
+	}
+}
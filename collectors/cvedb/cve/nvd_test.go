@@ -0,0 +1,137 @@
+package cve
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVulnDBDataNVD(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/partial.json")
+	assert.NoError(t, err)
+	nvd, err := LoadNVDFeed("./testdata/nvd/nvdcve-2.0-2023.json")
+	assert.NoError(t, err)
+
+	// CVE-2023-1002 has no cvss on mitre and fails validation unless enriched from nvd
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithNVD(nvd), WithCvssDetails(true))
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 2)
+	for _, v := range kvd.Cves {
+		if v.ID != "CVE-2023-1002" {
+			// mitre cvss is kept and the nvd reference is already known
+			assert.NotEqual(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", v.CvssV3.Vector)
+			assert.Nil(t, v.DatabaseSpecific[nvdKey])
+			continue
+		}
+		assert.Equal(t, newCvssv3("CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 6.5), v.CvssV3)
+		assert.Equal(t, "3.1", v.CvssVersion)
+		assert.Equal(t, "Medium", v.Severity)
+		assert.Contains(t, v.Urls, "https://groups.google.com/g/kubernetes-security-announce/c/1002")
+		assert.Equal(t, map[string]interface{}{"fields": []string{"cvss", "references"}}, v.DatabaseSpecific[nvdKey])
+	}
+}
+
+func TestLoadNVDFeed(t *testing.T) {
+	b, err := os.ReadFile("./testdata/nvd/nvdcve-2.0-2023.json")
+	assert.NoError(t, err)
+	gzPath := filepath.Join(t.TempDir(), "nvdcve-2.0-2023.json.gz")
+	f, err := os.Create(gzPath)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write(b)
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+
+	nvd, err := LoadNVDFeed(gzPath)
+	assert.NoError(t, err)
+	assert.Len(t, nvd, 2)
+	vector, ver := nvd["CVE-2023-1002"].cvss()
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", vector)
+	assert.Equal(t, "3.1", ver)
+
+	_, err = LoadNVDFeed("./testdata/nvd/missing.json")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	// a plain json file is not gzipped
+	plain := filepath.Join(t.TempDir(), "plain.json.gz")
+	assert.NoError(t, os.WriteFile(plain, b, 0600))
+	_, err = LoadNVDFeed(plain)
+	assert.ErrorIs(t, err, ErrDecode)
+}
+
+func TestParseVulnDBDataSharedRateLimit(t *testing.T) {
+	var requests int32
+	count := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			next(w, r)
+		}
+	}
+	mitre := httptest.NewServer(count(serveMitreRecord))
+	t.Cleanup(mitre.Close)
+	nvdAPI := httptest.NewServer(count(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/nvd/nvdcve-2.0-2023.json")
+	}))
+	t.Cleanup(nvdAPI.Close)
+	b, err := os.ReadFile("./testdata/feed/partial.json")
+	assert.NoError(t, err)
+
+	// a fake clock jumping to each slot the limiter hand out, so nothing sleeps
+	var mu sync.Mutex
+	start := time.Unix(0, 0)
+	clock := start
+	slots := make([]time.Duration, 0)
+	c := newCollector(WithMitreURL(mitre.URL), WithNVDURL(nvdAPI.URL), WithRateLimit(20))
+	c.rateLimiter.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return clock
+	}
+	c.rateLimiter.sleep = func(_ context.Context, until time.Time) error {
+		mu.Lock()
+		defer mu.Unlock()
+		slots = append(slots, until.Sub(start))
+		if until.After(clock) {
+			clock = until
+		}
+		return nil
+	}
+	kvd, err := c.parseVulnDBData(context.Background(), b)
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 2)
+	// two mitre records then two nvd lookups, all spaced by the one limiter
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	interval := 50 * time.Millisecond
+	assert.Equal(t, []time.Duration{0, interval, 2 * interval, 3 * interval}, slots)
+}
+
+func TestParseVulnDBDataSharedRetryBudget(t *testing.T) {
+	var nvdCalls int32
+	nvdAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&nvdCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(nvdAPI.Close)
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+
+	// 3 cves each retried up to 5 times, the budget stop retrying after the first 2 retries
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithNVDURL(nvdAPI.URL), WithRetries(5), WithRetryBudget(2),
+		WithRetryBackoff(time.Millisecond, time.Millisecond))
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 3)
+	assert.Equal(t, int32(3+2), atomic.LoadInt32(&nvdCalls))
+}
@@ -0,0 +1,417 @@
+package cve
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+const (
+	// defaultMaxResponseSize is the max number of bytes read from a single upstream response
+	defaultMaxResponseSize int64 = 10 << 20
+)
+
+type options struct {
+	client           *http.Client
+	maxResponseSize  int64
+	mitreURL         string
+	partialResults   bool
+	cveList          fs.FS
+	sourcePriority   []string
+	retries          int
+	retryBaseDelay   time.Duration
+	retryMaxDelay    time.Duration
+	rateLimit        float64
+	retryBudget      int
+	randSource       rand.Source
+	backoff          *backoff
+	timeout          time.Duration
+	preOneHandling   bool
+	minorLines       bool
+	gaFixed          bool
+	weighted         bool
+	allowComponents  []string
+	fallback         string
+	enricher         Enricher
+	feedParser       FeedParser
+	overrides        Overrides
+	nvd              NVDIndex
+	nvdURL           string
+	severityTable    utils.SeverityTable
+	tagTable         utils.ExploitabilityTagTable
+	cvssDetails      bool
+	fixedOnly        bool
+	strict           bool
+	resources        bool
+	reserved         bool
+	minYear          int
+	changedSince     int
+	minCves          int
+	collectorInfo    bool
+	now              func() time.Time
+	lint             bool
+	duplicateRanges  bool
+	maxComponents    int
+	releaseCheck     bool
+	releases         Releases
+	reconcile        bool
+	possiblyAffected bool
+	ecosystemRanges  bool
+	stats            *CollectStats
+	concurrency      int
+	stream           func(*Vulnerability)
+	slowestFetches   int
+	rootCAs          *x509.CertPool
+	clientCerts      []tls.Certificate
+
+	// rateLimiter and retries are shared by the fetches of every upstream
+	rateLimiter *rateLimiter
+	retriesLeft *int32
+	// adaptiveConcurrency set limiter, bounding the concurrency workers by upstream throttling
+	adaptiveConcurrency bool
+	limiter             *aimdLimiter
+}
+
+type option func(*options)
+
+// WithMaxResponseSize set the max number of bytes read from a single upstream response body
+func WithMaxResponseSize(size int64) option {
+	return func(o *options) {
+		o.maxResponseSize = size
+	}
+}
+
+// WithMitreURL set mitre cve api base url
+func WithMitreURL(url string) option {
+	return func(o *options) {
+		o.mitreURL = url
+	}
+}
+
+// WithPartialResults return the valid subset of collected cves alongside the validation errors
+// instead of failing the whole collection
+func WithPartialResults() option {
+	return func(o *options) {
+		o.partialResults = true
+	}
+}
+
+// WithCVEList read mitre records from a cve list v5 record tree (see OpenCVEList) instead of the mitre api
+func WithCVEList(cveList fs.FS) option {
+	return func(o *options) {
+		o.cveList = cveList
+	}
+}
+
+const (
+	// SourceAPI is the mitre cve api
+	SourceAPI = "api"
+	// SourceCVEList is the cve list record tree set with WithCVEList
+	SourceCVEList = "cvelist"
+)
+
+// WithSourcePriority set the order mitre record sources are tried in for each cve, e.g. SourceAPI then SourceCVEList
+// to fall back to the bulk list mirror when the api is down
+func WithSourcePriority(sources ...string) option {
+	return func(o *options) {
+		o.sourcePriority = sources
+	}
+}
+
+// WithRetries set how many times a transient upstream failure is retried
+func WithRetries(retries int) option {
+	return func(o *options) {
+		o.retries = retries
+	}
+}
+
+// WithRetryBackoff set the base and max delay of the exponential retry backoff
+func WithRetryBackoff(base, max time.Duration) option {
+	return func(o *options) {
+		o.retryBaseDelay = base
+		o.retryMaxDelay = max
+	}
+}
+
+// WithRateLimit space the requests to every upstream (mitre and nvd alike) to at most perSecond per second
+func WithRateLimit(perSecond float64) option {
+	return func(o *options) {
+		o.rateLimit = perSecond
+	}
+}
+
+// WithRetryBudget cap the retries of a collection across every upstream, once spent failures are not retried.
+// default to no cap, each fetch retrying up to WithRetries times
+func WithRetryBudget(retries int) option {
+	return func(o *options) {
+		o.retryBudget = retries
+	}
+}
+
+// WithRandSource set the source of randomness used for retry jitter, a fixed seed make runs reproducible
+func WithRandSource(src rand.Source) option {
+	return func(o *options) {
+		o.randSource = src
+	}
+}
+
+// WithTimeout set a wall clock budget for the entire collection
+func WithTimeout(timeout time.Duration) option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithPreOneHandling set whether records with only 0.x versions keep their exact bounds and separate
+// minor lines instead of the 1.x heuristics (introduced widened to 0 or to the minor line start), default enabled
+func WithPreOneHandling(enabled bool) option {
+	return func(o *options) {
+		o.preOneHandling = enabled
+	}
+}
+
+// WithMinorLines set whether a two-segment version (e.g. 1.24) mean its whole minor line, merged with the
+// consecutive lines into one range, or exactly its first release (1.24.0), default enabled
+func WithMinorLines(enabled bool) option {
+	return func(o *options) {
+		o.minorLines = enabled
+	}
+}
+
+// WithGAFixedVersions report the release of pre-release fixed versions (e.g. 1.25.0 for 1.25.0-beta.1)
+// instead of the exact fixed version mitre provides
+func WithGAFixedVersions() option {
+	return func(o *options) {
+		o.gaFixed = true
+	}
+}
+
+// WithWeightedComponents detect the component of a description by keyword weight (see utils.ComponentScores)
+// instead of mention count, picking the primary component of descriptions naming several. the weighting is
+// opt-in: utils.GetComponentFromDescriptionAndffected keep counting mentions, so the default components do
+// not change
+func WithWeightedComponents() option {
+	return func(o *options) {
+		o.weighted = true
+	}
+}
+
+// WithEcosystemRanges emit next to each semver range its ECOSYSTEM twin carrying the kubernetes release
+// versions, e.g. v1.24.14
+func WithEcosystemRanges() option {
+	return func(o *options) {
+		o.ecosystemRanges = true
+	}
+}
+
+// WithFallbackComponent set the component (e.g. kubernetes/kubernetes) of cves no component is detected for,
+// flagged with ComponentFallback, instead of skipping them
+func WithFallbackComponent(component string) option {
+	return func(o *options) {
+		o.fallback = component
+	}
+}
+
+// WithAllowComponents restrict collection to cves whose resolved component (e.g. k8s.io/apiserver) is listed
+func WithAllowComponents(components ...string) option {
+	return func(o *options) {
+		o.allowComponents = components
+	}
+}
+
+// Enricher add custom data (e.g. internal ticket links or tags) to a collected vulnerability
+type Enricher func(*Vulnerability) error
+
+// WithEnricher set a custom enricher invoked for each collected vulnerability before validation
+func WithEnricher(enricher Enricher) option {
+	return func(o *options) {
+		o.enricher = enricher
+	}
+}
+
+// WithSeverityTable set the table mapping cvss scores to severity labels, default to the standard cvss bands
+func WithSeverityTable(table utils.SeverityTable) option {
+	return func(o *options) {
+		o.severityTable = table
+	}
+}
+
+// WithExploitabilityTags set the table mapping cvss vector metrics to cves tags, default to
+// utils.DefaultExploitabilityTagTable
+func WithExploitabilityTags(table utils.ExploitabilityTagTable) option {
+	return func(o *options) {
+		o.tagTable = table
+	}
+}
+
+// WithCvssDetails set whether collected cves keep their cvss details: the cvss version, the parsed vector
+// components and the exploitability tags. default disabled, so published records keep their shape
+func WithCvssDetails(enabled bool) option {
+	return func(o *options) {
+		o.cvssDetails = enabled
+	}
+}
+
+// WithFixedOnly keep only cves with at least one range carrying a fixed event
+func WithFixedOnly() option {
+	return func(o *options) {
+		o.fixedOnly = true
+	}
+}
+
+// WithStrict fail on any mitre version that can not be confidently interpreted instead of skipping it,
+// so new upstream formats are caught early
+func WithStrict() option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithReserved emit a placeholder flagged reserved for cves whose mitre record is reserved but not yet published,
+// placeholders skip the usual validation requirements
+func WithReserved() option {
+	return func(o *options) {
+		o.reserved = true
+	}
+}
+
+// WithPossiblyAffected collect mitre versions with an unknown status into the cve PossiblyAffected ranges
+func WithPossiblyAffected() option {
+	return func(o *options) {
+		o.possiblyAffected = true
+	}
+}
+
+// WithMinYear skip cves whose id year (e.g. 2018 for CVE-2018-1002105) is before year
+func WithMinYear(year int) option {
+	return func(o *options) {
+		o.minYear = year
+	}
+}
+
+// WithChangedSince skip cves whose mitre record was not updated in the last days, falling back to the feed
+// date_published for records without dateUpdated
+func WithChangedSince(days int) option {
+	return func(o *options) {
+		o.changedSince = days
+	}
+}
+
+// WithMinCves fail the collection when fewer than n cves are collected, so an upstream change silently
+// emptying the database is caught
+func WithMinCves(n int) option {
+	return func(o *options) {
+		o.minCves = n
+	}
+}
+
+// WithResources populate cves resources with the api resources (e.g. apps/v1/Deployment) named by their description
+func WithResources() option {
+	return func(o *options) {
+		o.resources = true
+	}
+}
+
+// WithRootCAs set the CA pool used to verify upstream servers (e.g. a TLS inspecting proxy or internal mirror),
+// default to the system roots
+func WithRootCAs(pool *x509.CertPool) option {
+	return func(o *options) {
+		o.rootCAs = pool
+	}
+}
+
+// WithClientCertificate set a client certificate presented to upstream servers requiring mutual TLS
+func WithClientCertificate(cert tls.Certificate) option {
+	return func(o *options) {
+		o.clientCerts = append(o.clientCerts, cert)
+	}
+}
+
+func newOptions(opts ...option) *options {
+	o := &options{
+		client:          http.DefaultClient,
+		maxResponseSize: defaultMaxResponseSize,
+		mitreURL:        mitreURL,
+		feedParser:      JSONFeedParser{},
+		retryBaseDelay:  defaultRetryBaseDelay,
+		retryMaxDelay:   defaultRetryMaxDelay,
+		preOneHandling:  true,
+		minorLines:      true,
+		releases:        KubernetesReleases,
+		now:             time.Now,
+		severityTable:   utils.DefaultSeverityTable,
+		tagTable:        utils.DefaultExploitabilityTagTable,
+		slowestFetches:  defaultSlowestFetches,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.rootCAs != nil || len(o.clientCerts) > 0 {
+		o.client = tlsClient(o.rootCAs, o.clientCerts)
+	}
+	if o.randSource == nil {
+		o.randSource = rand.NewSource(time.Now().UnixNano())
+	}
+	o.backoff = newBackoff(o.retryBaseDelay, o.retryMaxDelay, o.randSource)
+	if o.rateLimit > 0 {
+		o.rateLimiter = newRateLimiter(time.Duration(float64(time.Second) / o.rateLimit))
+	}
+	if o.retryBudget > 0 {
+		left := int32(o.retryBudget)
+		o.retriesLeft = &left
+	}
+	if o.adaptiveConcurrency && o.concurrency > 1 {
+		o.limiter = newAIMDLimiter(o.concurrency)
+	}
+	return o
+}
+
+// tlsClient return an http client with the default transport settings and a custom tls config
+func tlsClient(rootCAs *x509.CertPool, certs []tls.Certificate) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: certs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return &http.Client{Transport: transport}
+}
+
+// collector fetch and parse k8s vulndb and mitre cve data
+type collector struct {
+	*options
+}
+
+func newCollector(opts ...option) collector {
+	return collector{
+		options: newOptions(opts...),
+	}
+}
+
+// withTimeout derive ctx bounded by the collection timeout budget when set
+func (c collector) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// componentAllowed check resolved component against the allowed components, all allowed when none set
+func (c collector) componentAllowed(component string) bool {
+	if len(c.allowComponents) == 0 {
+		return true
+	}
+	for _, ac := range c.allowComponents {
+		if strings.EqualFold(strings.TrimSpace(ac), component) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+// overrideKey is the database_specific key recording the override applied to a cve
+const overrideKey = "override"
+
+// Override is a curated correction of a cve known to be wrong upstream, only set fields replace the collected ones.
+// a score is overridden along with its vector, so the vector components match the score
+type Override struct {
+	Severity string     `json:"severity,omitempty"`
+	Vector   string     `json:"vector,omitempty"`
+	Score    float64    `json:"score,omitempty"`
+	Ranges   []*Version `json:"ranges,omitempty"`
+	// Reason explain why the upstream data is overridden
+	Reason string `json:"reason,omitempty"`
+}
+
+// Overrides map cve ids to their override
+type Overrides map[string]Override
+
+// LoadOverrides read an overrides json file mapping cve ids to their override
+func LoadOverrides(path string) (Overrides, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overrides Overrides
+	if err := json.Unmarshal(b, &overrides); err != nil {
+		return nil, wrapError(ErrDecode, fmt.Errorf("overrides file %s: %w", path, err))
+	}
+	for id, o := range overrides {
+		if o.Score > 0 && len(o.Vector) == 0 {
+			return nil, wrapError(ErrDecode, fmt.Errorf("overrides file %s: %s score override vector is mssing", path, id))
+		}
+	}
+	return overrides, nil
+}
+
+// WithOverrides set curated overrides applied to collected cves before enrichment and validation
+func WithOverrides(overrides Overrides) option {
+	return func(o *options) {
+		o.overrides = overrides
+	}
+}
+
+// applyOverrides apply the override of each cve and record the overridden fields in its database_specific.
+// a vector override without score take the vector base score, without severity the severity is recomputed
+// from the severity table
+func (c collector) applyOverrides(cves []*Vulnerability) {
+	if len(c.overrides) == 0 {
+		return
+	}
+	for _, cve := range cves {
+		o, ok := c.overrides[cve.ID]
+		if !ok {
+			continue
+		}
+		fields := make([]string, 0)
+		if len(o.Vector) > 0 {
+			score := o.Score
+			if score == 0 {
+				_, score = utils.CvssVectorToSeverity(o.Vector, c.severityTable)
+			}
+			cve.CvssV3 = newCvssv3(o.Vector, score)
+			cve.Severity = c.severityTable.Severity(score)
+			fields = append(fields, "vector")
+		}
+		if o.Score > 0 {
+			cve.CvssV3.Score = o.Score
+			cve.Severity = c.severityTable.Severity(o.Score)
+			fields = append(fields, "score")
+		}
+		if len(o.Severity) > 0 {
+			cve.Severity = o.Severity
+			fields = append(fields, "severity")
+		}
+		if len(o.Ranges) > 0 {
+			cve.AffectedVersions = o.Ranges
+			cve.Affected = GetAffectedEvents(cve)
+			fields = append(fields, "ranges")
+		}
+		if cve.DatabaseSpecific == nil {
+			cve.DatabaseSpecific = make(map[string]interface{})
+		}
+		provenance := map[string]interface{}{"fields": fields}
+		if len(o.Reason) > 0 {
+			provenance["reason"] = o.Reason
+		}
+		cve.DatabaseSpecific[overrideKey] = provenance
+	}
+}
+
+// overridden check if field of cve was set by an override, the provenance fields are strings once read back
+// from an exported file
+func overridden(cve *Vulnerability, field string) bool {
+	provenance, ok := cve.DatabaseSpecific[overrideKey].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	switch fields := provenance["fields"].(type) {
+	case []string:
+		for _, f := range fields {
+			if f == field {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, f := range fields {
+			if f == field {
+				return true
+			}
+		}
+	}
+	return false
+}
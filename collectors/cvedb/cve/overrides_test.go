@@ -0,0 +1,73 @@
+package cve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVulnDBDataOverrides(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	overrides, err := LoadOverrides("./testdata/overrides.json")
+	assert.NoError(t, err)
+
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithOverrides(overrides), WithCvssDetails(true))
+	assert.NoError(t, err)
+	for _, v := range kvd.Cves {
+		if v.ID != "CVE-2023-1001" {
+			assert.Nil(t, v.DatabaseSpecific[overrideKey])
+			continue
+		}
+		assert.Equal(t, newCvssv3("CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", 6.5), v.CvssV3)
+		assert.Equal(t, "Medium", v.Severity)
+		assert.Equal(t, []*Affected{{Ranges: []*Range{{RangeType: semver, Events: []*Event{{Introduced: "1.24.0"}, {Fixed: "1.24.3"}}}}}}, v.Affected)
+		assert.Equal(t, map[string]interface{}{
+			"fields": []string{"vector", "score", "ranges"},
+			"reason": "upstream score is computed from a wrong vector",
+		}, v.DatabaseSpecific[overrideKey])
+	}
+}
+
+func TestOverridesValidation(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+
+	// a curated severity outside the score band is not a mismatch
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithOverrides(Overrides{"CVE-2023-1001": {Severity: "Critical"}}))
+	assert.NoError(t, err)
+	for _, v := range kvd.Cves {
+		if v.ID == "CVE-2023-1001" {
+			assert.Equal(t, "Critical", v.Severity)
+		}
+	}
+	assert.NoError(t, ValidateCveData(kvd.Cves))
+
+	// a vector override bring its score and components along
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithOverrides(Overrides{"CVE-2023-1001": {Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}),
+		WithCvssDetails(true))
+	assert.NoError(t, err)
+	for _, v := range kvd.Cves {
+		if v.ID == "CVE-2023-1001" {
+			assert.Equal(t, newCvssv3("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8), v.CvssV3)
+			assert.Equal(t, "Critical", v.Severity)
+		}
+	}
+}
+
+func TestLoadOverridesInvalid(t *testing.T) {
+	_, err := LoadOverrides("./testdata/missing.json")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	// a feed is not a cve id to override map
+	_, err = LoadOverrides("./testdata/feed/components.json")
+	assert.ErrorIs(t, err, ErrDecode)
+	// a score override would leave the collected vector stale
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"CVE-2023-1001": {"score": 6.5}}`), 0600))
+	_, err = LoadOverrides(path)
+	assert.ErrorIs(t, err, ErrDecode)
+}
@@ -0,0 +1,59 @@
+package cve
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// collectorKey is the database_specific key recording the collector version and collection time of a cve
+const collectorKey = "collector"
+
+// WithCollectorInfo set whether the collector version and collection time are recorded in each cve
+// database_specific, default disabled
+func WithCollectorInfo(enabled bool) option {
+	return func(o *options) {
+		o.collectorInfo = enabled
+	}
+}
+
+// WithClock set the clock stamping WithCollectorInfo collection times, default to time.Now
+func WithClock(now func() time.Time) option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// collectorVersion return the collector module version from build info, its vcs revision for a devel build
+// or "devel" when neither is known
+func collectorVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if len(info.Main.Version) > 0 && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) > 0 {
+			return s.Value
+		}
+	}
+	return "devel"
+}
+
+// annotateCollector add the collector version and collection time to cves database_specific
+func (c collector) annotateCollector(cves []*Vulnerability) {
+	if !c.collectorInfo {
+		return
+	}
+	info := map[string]interface{}{
+		"version":      collectorVersion(),
+		"collected_at": c.now().UTC().Format(time.RFC3339),
+	}
+	for _, cve := range cves {
+		if cve.DatabaseSpecific == nil {
+			cve.DatabaseSpecific = make(map[string]interface{})
+		}
+		cve.DatabaseSpecific[collectorKey] = info
+	}
+}
@@ -0,0 +1,32 @@
+package cve
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVulnDBDataCollectorInfo(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/components.json")
+	assert.NoError(t, err)
+	collectedAt := time.Date(2023, 8, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	kvd, err := ParseVulnDBData(b, WithMitreURL(ts.URL), WithCollectorInfo(true), WithClock(func() time.Time { return collectedAt }))
+	assert.NoError(t, err)
+	assert.Len(t, kvd.Cves, 3)
+	for _, v := range kvd.Cves {
+		info, ok := v.DatabaseSpecific[collectorKey].(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "2023-08-01T10:30:00Z", info["collected_at"])
+		assert.NotEmpty(t, info["version"])
+	}
+
+	kvd, err = ParseVulnDBData(b, WithMitreURL(ts.URL))
+	assert.NoError(t, err)
+	for _, v := range kvd.Cves {
+		assert.Nil(t, v.DatabaseSpecific[collectorKey])
+	}
+}
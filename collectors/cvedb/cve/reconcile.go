@@ -0,0 +1,50 @@
+package cve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithReconcile cross-check the collected ranges against the versions stated in the feed content text,
+// each fixed or last affected version stated by the feed but missing from the ranges is reported as a warning
+func WithReconcile() option {
+	return func(o *options) {
+		o.reconcile = true
+	}
+}
+
+// reconcileFeedVersions flag cves whose ranges disagree with the versions stated in their feed content text,
+// texts map cve ids to their feed content text
+func reconcileFeedVersions(cves []*Vulnerability, texts map[string]string) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	for _, cve := range cves {
+		stated := textAffectedVersions(texts[cve.ID])
+		if len(stated) == 0 {
+			continue
+		}
+		bounds := make(map[string]bool)
+		for _, a := range cve.Affected {
+			for _, r := range a.Ranges {
+				for _, e := range r.Events {
+					bounds[e.Fixed], bounds[e.LastAffected] = true, true
+				}
+			}
+		}
+		missing := make([]string, 0)
+		for _, v := range stated {
+			for _, bound := range []string{v.Fixed, v.LastAffected} {
+				if len(bound) > 0 && !bounds[bound] {
+					missing = append(missing, bound)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+		issues = append(issues, ValidationIssue{CveID: cve.ID, Code: IssueFeedVersionMismatch,
+			Message: fmt.Sprintf("Feed stated versions %s are missing from ranges", strings.Join(missing, ", ")), Warning: true})
+	}
+	return issues
+}
@@ -0,0 +1,34 @@
+package cve
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVulnDBDataReconcile(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/reconcile.json")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts []option
+		want []ValidationIssue
+	}{
+		{name: "without reconcile", opts: []option{WithMitreURL(ts.URL)}},
+		{name: "feed and ranges disagree", opts: []option{WithMitreURL(ts.URL), WithReconcile()}, want: []ValidationIssue{
+			{CveID: "CVE-2023-1001", Code: IssueFeedVersionMismatch, Message: "Feed stated versions 1.24.3 are missing from ranges", Warning: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &CollectStats{}
+			kvd, err := ParseVulnDBData(b, append(tt.opts, WithStats(stats))...)
+			assert.NoError(t, err)
+			assert.Len(t, kvd.Cves, 2)
+			assert.Equal(t, tt.want, stats.ValidationIssues)
+		})
+	}
+}
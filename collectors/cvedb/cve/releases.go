@@ -0,0 +1,97 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Releases map kubernetes minor lines (e.g. 1.24) to their last patch release, -1 for a line still getting
+// patch releases
+type Releases map[string]int
+
+// KubernetesReleases is the bundled list of released kubernetes lines, end of life lines with their last patch
+// and supported lines with -1. lines released since are unknown, load a newer list with LoadReleases and
+// WithReleases once they are out
+var KubernetesReleases = Releases{
+	"1.0": 7, "1.1": 8, "1.2": 7, "1.3": 10, "1.4": 12, "1.5": 8, "1.6": 13, "1.7": 16, "1.8": 15, "1.9": 11,
+	"1.10": 13, "1.11": 10, "1.12": 10, "1.13": 12, "1.14": 10, "1.15": 12, "1.16": 15, "1.17": 17, "1.18": 20,
+	"1.19": 16, "1.20": 15, "1.21": 14, "1.22": 17, "1.23": 17, "1.24": 17, "1.25": 16, "1.26": 15, "1.27": 16,
+	"1.28": 15, "1.29": 15, "1.30": 14, "1.31": -1, "1.32": -1, "1.33": -1,
+}
+
+// LoadReleases read a releases json file mapping minor lines to their last patch release
+func LoadReleases(path string) (Releases, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var releases Releases
+	if err := json.Unmarshal(b, &releases); err != nil {
+		return nil, wrapError(ErrDecode, fmt.Errorf("releases file %s: %w", path, err))
+	}
+	return releases, nil
+}
+
+// WithReleaseCheck log CheckReleases warnings of collected cves against KubernetesReleases after validation
+func WithReleaseCheck() option {
+	return func(o *options) {
+		o.releaseCheck = true
+	}
+}
+
+// WithReleases set the release lines used by WithReleaseCheck instead of the bundled KubernetesReleases
+func WithReleases(releases Releases) option {
+	return func(o *options) {
+		o.releases = releases
+	}
+}
+
+// kubernetesComponent check if component is released along kubernetes, other org components have their own versions
+func kubernetesComponent(component string) bool {
+	return strings.HasPrefix(component, "k8s.io/")
+}
+
+// released check if ver is a release of a known line, a prerelease is checked as its line release
+func (r Releases) released(ver string) bool {
+	if normalizeZeroVersion(ver) == "0" {
+		return true
+	}
+	v, err := version.NewVersion(ver)
+	if err != nil {
+		return false
+	}
+	segments := v.Segments()
+	last, ok := r[fmt.Sprintf("%d.%d", segments[0], segments[1])]
+	return ok && (last < 0 || segments[2] <= last)
+}
+
+// CheckReleases return a warning for each version of kubernetes component ranges that is not a known release,
+// e.g. a fixed 1.99.0, which usually point to a parse error
+func CheckReleases(cves []*Vulnerability, releases Releases) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	for _, cve := range cves {
+		if !kubernetesComponent(cve.Component) {
+			continue
+		}
+		for _, a := range cve.Affected {
+			for _, r := range a.Ranges {
+				if r.RangeType == ecosystem {
+					continue
+				}
+				for _, e := range r.Events {
+					for _, ver := range []string{e.Introduced, e.Fixed, e.LastAffected} {
+						if len(ver) > 0 && !releases.released(ver) {
+							issues = append(issues, ValidationIssue{CveID: cve.ID, Code: IssueUnknownRelease,
+								Message: fmt.Sprintf("Version %s is not a kubernetes release", ver), Warning: true})
+						}
+					}
+				}
+			}
+		}
+	}
+	return issues
+}
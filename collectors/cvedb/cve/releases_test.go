@@ -0,0 +1,47 @@
+package cve
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReleases(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		releases Releases
+		want     []ValidationIssue
+	}{
+		{name: "known releases", id: "CVE-2023-1001", releases: KubernetesReleases, want: []ValidationIssue{}},
+		{name: "nonexistent line", id: "CVE-2023-1002", releases: KubernetesReleases, want: []ValidationIssue{
+			{CveID: "CVE-2023-1002", Code: IssueUnknownRelease, Message: "Version 1.99.0 is not a kubernetes release", Warning: true},
+		}},
+		{name: "patch past the line last release", id: "CVE-2023-1003", releases: KubernetesReleases, want: []ValidationIssue{
+			{CveID: "CVE-2023-1003", Code: IssueUnknownRelease, Message: "Version 1.20.16 is not a kubernetes release", Warning: true},
+		}},
+		{name: "line past the bundled list", id: "CVE-2023-1006", releases: KubernetesReleases, want: []ValidationIssue{
+			{CveID: "CVE-2023-1006", Code: IssueUnknownRelease, Message: "Version 1.30.15 is not a kubernetes release", Warning: true},
+			{CveID: "CVE-2023-1006", Code: IssueUnknownRelease, Message: "Version 1.36.0 is not a kubernetes release", Warning: true},
+			{CveID: "CVE-2023-1006", Code: IssueUnknownRelease, Message: "Version 1.36.1 is not a kubernetes release", Warning: true},
+		}},
+		{name: "non kubernetes component", id: "CVE-2023-1004", releases: KubernetesReleases, want: []ValidationIssue{}},
+		{name: "custom releases", id: "CVE-2023-1005", releases: Releases{"1.24": -1, "1.99": 0}, want: []ValidationIssue{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CheckReleases(loadCves(t, "./testdata/vulndb/releases.json", tt.id), tt.releases))
+		})
+	}
+}
+
+func TestLoadReleases(t *testing.T) {
+	releases, err := LoadReleases("./testdata/releases.json")
+	assert.NoError(t, err)
+	assert.Equal(t, Releases{"1.27": 16, "1.28": -1}, releases)
+	_, err = LoadReleases("./testdata/feed/components.json")
+	assert.ErrorIs(t, err, ErrDecode)
+	_, err = LoadReleases("./testdata/missing.json")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
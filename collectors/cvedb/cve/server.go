@@ -0,0 +1,148 @@
+package cve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server serve on demand collection over http, GET /db return the collected vulndb and GET /cve/{id} a single cve.
+// a collection is cached for the server ttl, failed collections are not cached. collections run on the server
+// context, shared by the requests waiting on them, so a client going away does not cancel the others
+type Server struct {
+	c      collector
+	feeds  []string
+	ttl    time.Duration
+	now    func() time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	db          *K8sVulnDB
+	collectedAt time.Time
+	// collecting is the collection in flight, nil when none
+	collecting *collection
+}
+
+// collection is a collection shared by the requests waiting on it, done is closed once db and err are set
+type collection struct {
+	done chan struct{}
+	db   *K8sVulnDB
+	err  error
+}
+
+// NewServer return a server collecting cves from feeds (the official k8s vulndb feed when empty) with opts,
+// reusing a collection for ttl
+func NewServer(feeds []string, ttl time.Duration, opts ...option) *Server {
+	if len(feeds) == 0 {
+		feeds = []string{k8svulnDBURL}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{c: newCollector(opts...), feeds: feeds, ttl: ttl, now: time.Now, ctx: ctx, cancel: cancel}
+}
+
+// Close cancel the collection in flight
+func (s *Server) Close() {
+	s.cancel()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case r.URL.Path == "/db":
+		db, err := s.vulnDB(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, db)
+	case strings.HasPrefix(r.URL.Path, "/cve/"):
+		id := strings.TrimPrefix(r.URL.Path, "/cve/")
+		v, err := s.vulnerability(r, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if v == nil {
+			http.Error(w, fmt.Sprintf("cve %s not found", id), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, v)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// cached return the cached collection while it is younger than the ttl
+func (s *Server) cached() *K8sVulnDB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db != nil && s.now().Sub(s.collectedAt) < s.ttl {
+		return s.db
+	}
+	return nil
+}
+
+// vulnDB return the cached collection, collecting again once it is older than the ttl. concurrent requests
+// wait on a single collection, the lock is only held to check the cache and start it
+func (s *Server) vulnDB(r *http.Request) (*K8sVulnDB, error) {
+	if db := s.cached(); db != nil {
+		return db, nil
+	}
+	s.mu.Lock()
+	call := s.collecting
+	if call == nil {
+		call = &collection{done: make(chan struct{})}
+		s.collecting = call
+		go s.collect(call)
+	}
+	s.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.db, call.err
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+}
+
+// collect run call on the server context and cache its db on success
+func (s *Server) collect(call *collection) {
+	db, err := s.c.collectFromFeeds(s.ctx, s.feeds)
+	s.mu.Lock()
+	if err == nil {
+		s.db, s.collectedAt = db, s.now()
+	}
+	s.collecting = nil
+	s.mu.Unlock()
+	call.db, call.err = db, err
+	close(call.done)
+}
+
+// vulnerability return the cve id from the cached collection, without one only that cve is collected
+func (s *Server) vulnerability(r *http.Request, id string) (*Vulnerability, error) {
+	if db := s.cached(); db != nil {
+		for _, v := range db.Cves {
+			if strings.EqualFold(v.ID, id) {
+				return v, nil
+			}
+		}
+		return nil, nil
+	}
+	return s.c.collectCveFromFeeds(r.Context(), s.feeds, id)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := marshalIndent(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,128 @@
+package cve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	mitre := newMitreServer(t)
+	var feedRequests int32
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&feedRequests, 1)
+		http.ServeFile(w, r, "./testdata/feed/components.json")
+	}))
+	defer feed.Close()
+	now := time.Now()
+	s := NewServer([]string{feed.URL}, time.Hour, WithMitreURL(mitre.URL))
+	defer s.Close()
+	s.now = func() time.Time { return now }
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	get := func(path string, v interface{}) int {
+		resp, err := http.Get(ts.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	// without a cached collection only the requested cve is collected
+	var v Vulnerability
+	assert.Equal(t, http.StatusOK, get("/cve/CVE-2023-1003", &v))
+	assert.Equal(t, "CVE-2023-1003", v.ID)
+	assert.Equal(t, http.StatusNotFound, get("/cve/CVE-2099-0001", &v))
+	assert.Nil(t, s.cached())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&feedRequests))
+
+	var db K8sVulnDB
+	assert.Equal(t, http.StatusOK, get("/db", &db))
+	assert.Equal(t, 3, len(db.Cves))
+	assert.Equal(t, http.StatusOK, get("/cve/CVE-2023-1003", &v))
+	assert.Equal(t, "CVE-2023-1003", v.ID)
+	assert.Equal(t, http.StatusNotFound, get("/cve/CVE-2099-0001", &v))
+	assert.Equal(t, http.StatusNotFound, get("/unknown", &v))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&feedRequests))
+
+	// collection is refreshed once the ttl expired
+	now = now.Add(2 * time.Hour)
+	assert.Equal(t, http.StatusOK, get("/db", &db))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&feedRequests))
+}
+
+func TestServerSharedCollection(t *testing.T) {
+	mitre := newMitreServer(t)
+	var feedRequests int32
+	release := make(chan struct{})
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&feedRequests, 1)
+		<-release
+		http.ServeFile(w, r, "./testdata/feed/components.json")
+	}))
+	defer feed.Close()
+	s := NewServer([]string{feed.URL}, time.Hour, WithMitreURL(mitre.URL))
+	defer s.Close()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	// a client going away while the collection is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/db", nil)
+	assert.NoError(t, err)
+	gone := make(chan error, 1)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		gone <- err
+	}()
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 3)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/db")
+			if assert.NoError(t, err) {
+				statuses[i] = resp.StatusCode
+				resp.Body.Close()
+			}
+		}(i)
+	}
+	for atomic.LoadInt32(&feedRequests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	assert.Error(t, <-gone)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK}, statuses)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&feedRequests))
+}
+
+func TestServerUpstreamError(t *testing.T) {
+	feed := httptest.NewServer(http.NotFoundHandler())
+	defer feed.Close()
+	s := NewServer([]string{feed.URL}, time.Hour)
+	defer s.Close()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/db")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
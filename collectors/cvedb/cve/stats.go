@@ -0,0 +1,153 @@
+package cve
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultSlowestFetches is how many of the slowest cve record fetches are kept by default
+const defaultSlowestFetches = 10
+
+// CollectStats report where collection time was spent, filled when set with WithStats.
+// CveFetch and Parse are cumulative over cves, so they can exceed the run duration when collecting concurrently
+type CollectStats struct {
+	FeedFetch time.Duration
+	CveFetch  time.Duration
+	Parse     time.Duration
+	Validate  time.Duration
+	// SlowestFetches list the slowest cve record fetches, slowest first
+	SlowestFetches []CveFetch
+	// FeedItems is the number of items read from the feeds and EmittedCves the number of cves collected from them
+	FeedItems   int
+	EmittedCves int
+	// CollectedCves is the number of cves validated and ValidationIssues the issues found on them, see Summarize
+	CollectedCves    int
+	ValidationIssues []ValidationIssue
+	// SkippedCves list the feed cves not collected with the reason they were skipped
+	SkippedCves []SkippedCve
+
+	mu sync.Mutex
+}
+
+// SkippedCve is a feed cve not collected, Filtered is set when skipped by an option (e.g. WithMinYear) rather than
+// for lack of usable data
+type SkippedCve struct {
+	CveID    string
+	Reason   string
+	Filtered bool
+}
+
+// CveFetch is the time spent reading a single cve record
+type CveFetch struct {
+	CveID    string
+	Duration time.Duration
+}
+
+// WithStats record per phase timings and the slowest cve fetches of the collection on stats
+func WithStats(stats *CollectStats) option {
+	return func(o *options) {
+		o.stats = stats
+	}
+}
+
+// WithSlowestFetches set how many of the slowest cve fetches are kept on stats, default to 10
+func WithSlowestFetches(n int) option {
+	return func(o *options) {
+		o.slowestFetches = n
+	}
+}
+
+type phase int
+
+const (
+	phaseFeedFetch phase = iota
+	phaseParse
+	phaseValidate
+)
+
+// addPhase add d to the phase duration
+func (s *CollectStats) addPhase(p phase, d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch p {
+	case phaseFeedFetch:
+		s.FeedFetch += d
+	case phaseParse:
+		s.Parse += d
+	case phaseValidate:
+		s.Validate += d
+	}
+}
+
+// Coverage return the ratio of emitted cves to feed items, a drop usually point to a parsing regression.
+// an item listing several cve ids can push it above 1
+func (s *CollectStats) Coverage() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.FeedItems == 0 {
+		return 0
+	}
+	return float64(s.EmittedCves) / float64(s.FeedItems)
+}
+
+// addFeedItems add n feed items read
+func (s *CollectStats) addFeedItems(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FeedItems += n
+}
+
+// setEmitted set the number of cves emitted by the run
+func (s *CollectStats) setEmitted(db *K8sVulnDB) {
+	if s == nil || db == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.EmittedCves = len(db.Cves)
+}
+
+// addValidation add n validated cves and their issues
+func (s *CollectStats) addValidation(n int, issues []ValidationIssue) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CollectedCves += n
+	s.ValidationIssues = append(s.ValidationIssues, issues...)
+}
+
+// addSkipped add a skipped cve
+func (s *CollectStats) addSkipped(skipped SkippedCve) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SkippedCves = append(s.SkippedCves, skipped)
+}
+
+// recordFetch add a cve record fetch to the cve fetch phase and keep it when among the limit slowest ones
+func (s *CollectStats) recordFetch(cveID string, d time.Duration, limit int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CveFetch += d
+	s.SlowestFetches = append(s.SlowestFetches, CveFetch{CveID: cveID, Duration: d})
+	sort.SliceStable(s.SlowestFetches, func(i, j int) bool {
+		return s.SlowestFetches[i].Duration > s.SlowestFetches[j].Duration
+	})
+	if len(s.SlowestFetches) > limit {
+		s.SlowestFetches = s.SlowestFetches[:limit]
+	}
+}
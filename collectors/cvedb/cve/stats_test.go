@@ -0,0 +1,53 @@
+package cve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectStats(t *testing.T) {
+	mitre := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Base(r.URL.Path) == "CVE-2023-1004" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		serveMitreRecord(w, r)
+	}))
+	defer mitre.Close()
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./testdata/feed/components.json")
+	}))
+	defer feed.Close()
+
+	stats := &CollectStats{}
+	kvd, err := CollectFromFeeds(context.Background(), []string{feed.URL}, WithMitreURL(mitre.URL), WithStats(stats), WithSlowestFetches(2))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(kvd.Cves))
+	assert.Greater(t, stats.FeedFetch, time.Duration(0))
+	assert.GreaterOrEqual(t, stats.CveFetch, 50*time.Millisecond)
+	assert.Greater(t, stats.Parse, time.Duration(0))
+	assert.Greater(t, stats.Validate, time.Duration(0))
+	assert.Equal(t, 2, len(stats.SlowestFetches))
+	assert.Equal(t, "CVE-2023-1004", stats.SlowestFetches[0].CveID)
+	assert.GreaterOrEqual(t, stats.SlowestFetches[0].Duration, stats.SlowestFetches[1].Duration)
+}
+
+func TestCollectStatsCoverage(t *testing.T) {
+	ts := newMitreServer(t)
+	b, err := os.ReadFile("./testdata/feed/partial.json")
+	assert.NoError(t, err)
+	stats := &CollectStats{}
+	_, err = ParseVulnDBData(b, WithMitreURL(ts.URL), WithPartialResults(), WithStats(stats))
+	assert.Error(t, err)
+	assert.Equal(t, 2, stats.FeedItems)
+	assert.Equal(t, 1, stats.EmittedCves)
+	assert.Equal(t, 0.5, stats.Coverage())
+
+	assert.Equal(t, float64(0), (&CollectStats{}).Coverage())
+}
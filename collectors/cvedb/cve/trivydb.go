@@ -0,0 +1,160 @@
+package cve
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// trivy db bucket layout of k8s advisories, see github.com/aquasecurity/trivy-db
+const (
+	trivyDataSourceBucket    = "data-source"
+	trivyVulnerabilityBucket = "vulnerability"
+	trivySourceID            = "k8s"
+	trivySourceName          = "Official Kubernetes CVE Feed"
+)
+
+// TrivyK8sBucket is the trivy db bucket of k8s advisories, holding a bucket per component keyed by cve id
+var TrivyK8sBucket = trivySourceID + "::" + trivySourceName
+
+// BucketWriter put a value in a nested bucket, creating the buckets on the way, e.g. a bbolt read-write
+// transaction wrapper
+type BucketWriter interface {
+	Put(buckets []string, key string, value []byte) error
+}
+
+// boltBuckets is a BucketWriter on a bbolt read-write transaction
+type boltBuckets struct {
+	tx *bolt.Tx
+}
+
+func (b boltBuckets) Put(buckets []string, key string, value []byte) error {
+	bucket, err := b.tx.CreateBucketIfNotExists([]byte(buckets[0]))
+	if err != nil {
+		return err
+	}
+	for _, name := range buckets[1:] {
+		if bucket, err = bucket.CreateBucketIfNotExists([]byte(name)); err != nil {
+			return err
+		}
+	}
+	return bucket.Put([]byte(key), value)
+}
+
+// WriteTrivyBoltDB write db to the trivy bolt db file at path, in a single transaction so a failed write
+// leave no partial advisories
+func WriteTrivyBoltDB(db *K8sVulnDB, path string) error {
+	bdb, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return fmt.Errorf("failed to open trivy db %s: %w", path, err)
+	}
+	if err := bdb.Update(func(tx *bolt.Tx) error {
+		return WriteTrivyDB(db, boltBuckets{tx: tx})
+	}); err != nil {
+		bdb.Close()
+		return err
+	}
+	return bdb.Close()
+}
+
+// TrivyAdvisory is the advisory trivy match a component version against
+type TrivyAdvisory struct {
+	VulnerableVersions []string `json:",omitempty"`
+	PatchedVersions    []string `json:",omitempty"`
+}
+
+// TrivyVulnerability is the vulnerability details trivy report on a match
+type TrivyVulnerability struct {
+	Title       string               `json:",omitempty"`
+	Description string               `json:",omitempty"`
+	Severity    string               `json:",omitempty"`
+	CVSS        map[string]TrivyCVSS `json:",omitempty"`
+	References  []string             `json:",omitempty"`
+}
+
+type TrivyCVSS struct {
+	V3Vector string  `json:",omitempty"`
+	V3Score  float64 `json:",omitempty"`
+}
+
+type TrivyDataSource struct {
+	ID   string `json:",omitempty"`
+	Name string `json:",omitempty"`
+	URL  string `json:",omitempty"`
+}
+
+// WriteTrivyDB write db in the trivy db bucket layout: an advisory per cve in the component bucket of
+// TrivyK8sBucket, the cve details in the vulnerability bucket and the k8s feed in the data-source bucket
+func WriteTrivyDB(db *K8sVulnDB, w BucketWriter) error {
+	source, err := json.Marshal(TrivyDataSource{ID: trivySourceID, Name: trivySourceName, URL: k8svulnDBURL})
+	if err != nil {
+		return err
+	}
+	if err := w.Put([]string{trivyDataSourceBucket}, TrivyK8sBucket, source); err != nil {
+		return fmt.Errorf("trivy db data source: %w", err)
+	}
+	for _, v := range db.Cves {
+		if v.Reserved || len(v.Component) == 0 {
+			continue
+		}
+		advisory, err := json.Marshal(trivyAdvisory(v))
+		if err != nil {
+			return err
+		}
+		if err := w.Put([]string{TrivyK8sBucket, v.Component}, v.ID, advisory); err != nil {
+			return fmt.Errorf("trivy db advisory %s: %w", v.ID, err)
+		}
+		details, err := json.Marshal(trivyVulnerability(v))
+		if err != nil {
+			return err
+		}
+		if err := w.Put([]string{trivyVulnerabilityBucket}, v.ID, details); err != nil {
+			return fmt.Errorf("trivy db vulnerability %s: %w", v.ID, err)
+		}
+	}
+	return nil
+}
+
+// trivyAdvisory return the semver ranges of v as trivy version constraints, e.g. ">=1.24.0, <1.24.14"
+func trivyAdvisory(v *Vulnerability) TrivyAdvisory {
+	var advisory TrivyAdvisory
+	for _, a := range v.Affected {
+		for _, r := range a.Ranges {
+			if r.RangeType != semver {
+				continue
+			}
+			constraints := make([]string, 0, 2)
+			for _, e := range r.Events {
+				switch {
+				case len(e.Introduced) > 0 && e.Introduced != "0":
+					constraints = append(constraints, ">="+e.Introduced)
+				case len(e.Fixed) > 0:
+					constraints = append(constraints, "<"+e.Fixed)
+					advisory.PatchedVersions = append(advisory.PatchedVersions, e.Fixed)
+				case len(e.LastAffected) > 0:
+					constraints = append(constraints, "<="+e.LastAffected)
+				}
+			}
+			if len(constraints) > 0 {
+				advisory.VulnerableVersions = append(advisory.VulnerableVersions, strings.Join(constraints, ", "))
+			}
+		}
+	}
+	return advisory
+}
+
+func trivyVulnerability(v *Vulnerability) TrivyVulnerability {
+	t := TrivyVulnerability{
+		Title:       v.Summary,
+		Description: v.Description,
+		Severity:    strings.ToUpper(v.Severity),
+		References:  v.Urls,
+	}
+	if len(v.CvssV3.Vector) > 0 {
+		t.CVSS = map[string]TrivyCVSS{trivySourceID: {V3Vector: v.CvssV3.Vector, V3Score: v.CvssV3.Score}}
+	}
+	return t
+}
@@ -0,0 +1,73 @@
+package cve
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+// memBuckets is an in memory BucketWriter, values keyed by their bucket path then key
+type memBuckets map[string]map[string][]byte
+
+func (m memBuckets) Put(buckets []string, key string, value []byte) error {
+	path := strings.Join(buckets, "/")
+	if m[path] == nil {
+		m[path] = make(map[string][]byte)
+	}
+	m[path][key] = value
+	return nil
+}
+
+type failingBuckets struct{}
+
+func (failingBuckets) Put([]string, string, []byte) error {
+	return errors.New("bucket is read only")
+}
+
+func TestWriteTrivyDB(t *testing.T) {
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/trivy.json")}
+	buckets := make(memBuckets)
+	assert.NoError(t, WriteTrivyDB(db, buckets))
+
+	var advisory TrivyAdvisory
+	assert.NoError(t, json.Unmarshal(buckets[TrivyK8sBucket+"/k8s.io/kubelet"]["CVE-2023-1001"], &advisory))
+	assert.Equal(t, TrivyAdvisory{VulnerableVersions: []string{"<1.24.2", ">=1.25.0, <=1.25.3"}, PatchedVersions: []string{"1.24.2"}}, advisory)
+
+	var vulnerability TrivyVulnerability
+	assert.NoError(t, json.Unmarshal(buckets["vulnerability"]["CVE-2023-1001"], &vulnerability))
+	assert.Equal(t, "LOW", vulnerability.Severity)
+	assert.Equal(t, TrivyCVSS{V3Vector: "CVSS:3.1/AV:L/AC:L/PR:H/UI:N/S:U/C:L/I:L/A:N", V3Score: 3.4}, vulnerability.CVSS["k8s"])
+	assert.Nil(t, buckets["vulnerability"]["CVE-2023-1002"])
+
+	var source TrivyDataSource
+	assert.NoError(t, json.Unmarshal(buckets["data-source"][TrivyK8sBucket], &source))
+	assert.Equal(t, "k8s", source.ID)
+
+	assert.Error(t, WriteTrivyDB(db, failingBuckets{}))
+}
+
+func TestWriteTrivyBoltDB(t *testing.T) {
+	db := &K8sVulnDB{Cves: loadCves(t, "./testdata/vulndb/valid.json", "CVE-2023-1001")}
+	path := filepath.Join(t.TempDir(), "trivy.db")
+	assert.NoError(t, WriteTrivyBoltDB(db, path))
+
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	assert.NoError(t, err)
+	defer bdb.Close()
+	assert.NoError(t, bdb.View(func(tx *bolt.Tx) error {
+		source := tx.Bucket([]byte(TrivyK8sBucket))
+		assert.NotNil(t, source)
+		component := source.Bucket([]byte("k8s.io/kubelet"))
+		assert.NotNil(t, component)
+		var advisory TrivyAdvisory
+		assert.NoError(t, json.Unmarshal(component.Get([]byte("CVE-2023-1001")), &advisory))
+		assert.Equal(t, TrivyAdvisory{VulnerableVersions: []string{">=1.24.0, <1.24.2"}, PatchedVersions: []string{"1.24.2"}}, advisory)
+		assert.NotNil(t, tx.Bucket([]byte("vulnerability")).Get([]byte("CVE-2023-1001")))
+		return nil
+	}))
+}
@@ -0,0 +1,180 @@
+package cve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+// osvRangeTypes are the range types allowed by the OSV schema
+var osvRangeTypes = map[string]bool{semver: true, ecosystem: true, "GIT": true}
+
+// ValidationReport is the outcome of ValidateFile, returned as its error when the file has validation errors
+type ValidationReport struct {
+	Path   string
+	Cves   int
+	Issues []ValidationIssue
+}
+
+func (r *ValidationReport) Error() string {
+	return fmt.Sprintf("%s is invalid: %s", r.Path, strings.TrimSpace(Summarize(r.Cves, r.Issues)))
+}
+
+// ValidateFile load a database file written by the collector, a single cve, a json array of cves (e.g. a
+// WriteBySeverity file) or a K8sVulnDB, in the json or osv format, and validate it with ValidateCveData
+// after checking each record against its format schema. a *ValidationReport is returned on validation errors,
+// warnings alone do not fail validation
+func ValidateFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	records, err := fileRecords(b)
+	if err != nil {
+		return wrapError(ErrDecode, fmt.Errorf("validate file %s: %w", path, err))
+	}
+	report := &ValidationReport{Path: path, Cves: len(records)}
+	cves := make([]*Vulnerability, 0, len(records))
+	for _, record := range records {
+		cve, issues := decodeRecord(record)
+		report.Issues = append(report.Issues, issues...)
+		if cve != nil {
+			cves = append(cves, cve)
+		}
+	}
+	report.Issues = append(report.Issues, validationIssues(cves, utils.DefaultSeverityTable)...)
+	if issuesError(report.Issues) == nil {
+		return nil
+	}
+	return report
+}
+
+// fileRecords split a database file into its cve records
+func fileRecords(b []byte) ([]json.RawMessage, error) {
+	var records []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		err := json.Unmarshal(b, &records)
+		return records, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	if cves, ok := fields["Cves"]; ok {
+		err := json.Unmarshal(cves, &records)
+		return records, err
+	}
+	return []json.RawMessage{b}, nil
+}
+
+// decodeRecord decode an osv or json cve record, records with unknown fields or missing osv required fields
+// are reported as schema violations
+func decodeRecord(record json.RawMessage) (*Vulnerability, []ValidationIssue) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return nil, []ValidationIssue{{Code: IssueSchemaViolation, Message: fmt.Sprintf("Record is not an object: %s", err)}}
+	}
+	var issues issueList
+	_ = json.Unmarshal(fields["id"], &issues.cveID)
+	if _, ok := fields["schema_version"]; ok {
+		var o OSV
+		if err := decodeStrict(record, &o); err != nil {
+			issues.add(IssueSchemaViolation, "Record does not match the osv schema: %s", err)
+			if err := json.Unmarshal(record, &o); err != nil {
+				return nil, issues.issues
+			}
+		}
+		osvSchemaIssues(&o, &issues)
+		return o.toVulnerability(), issues.issues
+	}
+	var v Vulnerability
+	if err := decodeStrict(record, &v); err != nil {
+		issues.add(IssueSchemaViolation, "Record does not match the json schema: %s", err)
+		if err := json.Unmarshal(record, &v); err != nil {
+			return nil, issues.issues
+		}
+	}
+	return &v, issues.issues
+}
+
+// decodeStrict decode record into v, failing on fields unknown to v
+func decodeStrict(record json.RawMessage, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(record))
+	d.DisallowUnknownFields()
+	return d.Decode(v)
+}
+
+// osvSchemaIssues add the violations of osv required fields and range types
+func osvSchemaIssues(o *OSV, issues *issueList) {
+	if len(o.SchemaVersion) == 0 {
+		issues.add(IssueSchemaViolation, "schema_version is mssing")
+	}
+	if len(o.Modified) == 0 {
+		issues.add(IssueSchemaViolation, "modified is mssing")
+	}
+	for _, a := range o.Affected {
+		if len(a.Package.Ecosystem) == 0 || len(a.Package.Name) == 0 {
+			issues.add(IssueSchemaViolation, "Affected package ecosystem or name is mssing")
+		}
+		for _, r := range a.Ranges {
+			if !osvRangeTypes[r.RangeType] {
+				issues.add(IssueSchemaViolation, "Affected range type %q is not an osv range type", r.RangeType)
+			}
+			if fixedAndLastAffected(r) {
+				issues.add(IssueSchemaViolation, "Affected range has both fixed and last_affected events")
+			}
+		}
+	}
+}
+
+// fixedAndLastAffected check if a range has both fixed and last_affected events, forbidden by osv
+func fixedAndLastAffected(r *Range) bool {
+	var fixed, lastAffected bool
+	for _, e := range r.Events {
+		fixed = fixed || len(e.Fixed) > 0
+		lastAffected = lastAffected || len(e.LastAffected) > 0
+	}
+	return fixed && lastAffected
+}
+
+// toVulnerability convert an osv record back to a vulnerability, the cvss score dropped by ToOSV is
+// recomputed from the vector
+func (o *OSV) toVulnerability() *Vulnerability {
+	v := &Vulnerability{
+		ID:          o.ID,
+		CreatedAt:   o.Published,
+		Summary:     o.Summary,
+		Description: o.Details,
+	}
+	if len(v.CreatedAt) == 0 {
+		v.CreatedAt = o.Modified
+	}
+	for _, a := range o.Affected {
+		if len(v.Component) == 0 {
+			v.Component = a.Package.Name
+		}
+		v.Affected = append(v.Affected, &Affected{Ranges: a.Ranges, DatabaseSpecific: a.DatabaseSpecific})
+	}
+	for _, r := range o.References {
+		v.Urls = append(v.Urls, r.URL)
+	}
+	if len(o.Severity) > 0 {
+		_, score := utils.CvssVectorToSeverity(o.Severity[0].Score, utils.DefaultSeverityTable)
+		v.CvssV3 = newCvssv3(o.Severity[0].Score, score)
+	}
+	for k, val := range o.DatabaseSpecific {
+		if severity, ok := val.(string); ok && k == "severity" {
+			v.Severity = severity
+			continue
+		}
+		if v.DatabaseSpecific == nil {
+			v.DatabaseSpecific = make(map[string]interface{})
+		}
+		v.DatabaseSpecific[k] = val
+	}
+	return v
+}
@@ -0,0 +1,51 @@
+package cve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantIssues []ValidationIssue
+	}{
+		{name: "json cves", path: "./testdata/validate/good.json"},
+		{name: "osv cve", path: "./testdata/validate/good-osv.json"},
+		{name: "invalid json cves", path: "./testdata/validate/bad.json", wantIssues: []ValidationIssue{
+			{CveID: "CVE-2023-1001", Code: IssueSchemaViolation, Message: `Record does not match the json schema: json: unknown field "fixed_version"`},
+			{CveID: "CVE-2023-1001", Code: IssueMissingFixedVersion, Message: "FixedVersion is missing"},
+			{CveID: "CVE-2023-1001", Code: IssueSeverityMismatch, Message: "Severity Critical does not match score 3.4"},
+		}},
+		{name: "invalid osv cve", path: "./testdata/validate/bad-osv.json", wantIssues: []ValidationIssue{
+			{CveID: "CVE-2023-1001", Code: IssueSchemaViolation, Message: "modified is mssing"},
+			{CveID: "CVE-2023-1001", Code: IssueSchemaViolation, Message: `Affected range type "RANGE" is not an osv range type`},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFile(tt.path)
+			if len(tt.wantIssues) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var report *ValidationReport
+			assert.True(t, errors.As(err, &report))
+			assert.Equal(t, tt.path, report.Path)
+			assert.Equal(t, 1, report.Cves)
+			assert.Equal(t, tt.wantIssues, report.Issues)
+			assert.ErrorContains(t, err, "1 CVEs collected, 0 valid, 1 invalid")
+		})
+	}
+}
+
+func TestValidateFileDecodeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "truncated.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[{"id": "CVE-2023-1001"`), 0644))
+	assert.ErrorIs(t, ValidateFile(path), ErrDecode)
+}
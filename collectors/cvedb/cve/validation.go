@@ -0,0 +1,99 @@
+package cve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
+)
+
+// validation issue codes
+const (
+	IssueDuplicateID          = "duplicate-id"
+	IssueMissingID            = "missing-id"
+	IssueMissingCreatedAt     = "missing-created-at"
+	IssueMissingSummary       = "missing-summary"
+	IssueMissingComponent     = "missing-component"
+	IssueInvalidComponent     = "invalid-component"
+	IssueMissingDescription   = "missing-description"
+	IssueMissingFixedVersion  = "missing-fixed-version"
+	IssueUnsortedRanges       = "unsorted-ranges"
+	IssueEmptyRange           = "empty-range"
+	IssueInvalidVersion       = "invalid-version"
+	IssueMissingScore         = "missing-score"
+	IssueMissingVector        = "missing-vector"
+	IssueMissingSeverity      = "missing-severity"
+	IssueSeverityMismatch     = "severity-mismatch"
+	IssueMissingUrls          = "missing-urls"
+	IssueSummaryIsDescription = "summary-is-description"
+	IssueBoilerplateSummary   = "boilerplate-summary"
+	IssueFeedVersionMismatch  = "feed-version-mismatch"
+	IssueDuplicateRanges      = "duplicate-ranges"
+	IssueUnknownRelease       = "unknown-release"
+	IssueSchemaViolation      = "schema-violation"
+	IssueTooManyComponents    = "too-many-components"
+	IssueContradictoryRange   = "contradictory-range"
+)
+
+// ValidationIssue is a single finding on cve data, warnings point to likely parse problems without failing validation
+type ValidationIssue struct {
+	CveID   string
+	Code    string
+	Message string
+	Warning bool
+}
+
+func (vi ValidationIssue) Error() string {
+	return fmt.Sprintf("%s on cve #%s", vi.Message, vi.CveID)
+}
+
+// ValidateCveIssues return the ValidateCveData errors of cves as issues
+func ValidateCveIssues(cves []*Vulnerability) []ValidationIssue {
+	return validationIssues(cves, utils.DefaultSeverityTable)
+}
+
+// Summarize return a compact report of a validation: how many of the collected cves are valid and the
+// invalid cves grouped by issue code, most frequent first, warnings are grouped apart
+func Summarize(collected int, issues []ValidationIssue) string {
+	errors, warnings := make(map[string][]string), make(map[string][]string)
+	invalid := make(map[string]bool)
+	for _, issue := range issues {
+		group := errors
+		if issue.Warning {
+			group = warnings
+		} else {
+			invalid[issue.CveID] = true
+		}
+		if ids := group[issue.Code]; len(ids) == 0 || ids[len(ids)-1] != issue.CveID {
+			group[issue.Code] = append(ids, issue.CveID)
+		}
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d CVEs collected, %d valid, %d invalid\n", collected, collected-len(invalid), len(invalid))
+	writeGroups(&sb, "errors", errors)
+	writeGroups(&sb, "warnings", warnings)
+	return sb.String()
+}
+
+func writeGroups(sb *strings.Builder, title string, groups map[string][]string) {
+	if len(groups) == 0 {
+		return
+	}
+	codes := make([]string, 0, len(groups))
+	for code := range groups {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if len(groups[codes[i]]) != len(groups[codes[j]]) {
+			return len(groups[codes[i]]) > len(groups[codes[j]])
+		}
+		return codes[i] < codes[j]
+	})
+	fmt.Fprintf(sb, "%s:\n", title)
+	for _, code := range codes {
+		ids := groups[code]
+		sort.Strings(ids)
+		fmt.Fprintf(sb, "  %s: %d (%s)\n", code, len(ids), strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,18 @@
+package cve
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	cves := loadCves(t, "./testdata/vulndb/summary.json")
+	issues := append(ValidateCveIssues(cves), LintCveData(cves)...)
+	want, err := os.ReadFile("./testdata/summary.golden")
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), Summarize(len(cves), issues))
+
+	assert.Equal(t, "2 CVEs collected, 2 valid, 0 invalid\n", Summarize(2, nil))
+}
@@ -1,8 +1,6 @@
 package cvedb
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -11,8 +9,6 @@ import (
 	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/cve"
 	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
 	"golang.org/x/xerrors"
-
-	"os"
 )
 
 const (
@@ -31,6 +27,7 @@ func NewUpdater(opts ...option) Updater {
 		k8sdDir:   utils.K8sCveDir(),
 		cveFolder: filepath.Join(collectors.MainFolder, cveFolder),
 		version:   version,
+		format:    cve.FormatJSON,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -44,13 +41,95 @@ type options struct {
 	version   string
 	k8sdDir   string
 	cveFolder string
+	format    string
+	overrides string
+	nvdFeeds  []string
+	split     bool
+	minCves   int
+	info      bool
+	cvss      bool
 }
 
 type option func(*options)
 
+// WithOutputFormat set the format cve files are written in (json, osv or yaml)
+func WithOutputFormat(format string) option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithOverrides set the path of a curated overrides json file applied to collected cves
+func WithOverrides(path string) option {
+	return func(o *options) {
+		o.overrides = path
+	}
+}
+
+// WithNVDFeeds set the paths of locally downloaded nvd json 2.0 feed files used to enrich collected cves offline
+func WithNVDFeeds(paths ...string) option {
+	return func(o *options) {
+		o.nvdFeeds = paths
+	}
+}
+
+// WithSplitBySeverity set whether cves are written in one json file per severity (e.g. critical.json)
+// instead of one file per cve, default disabled
+func WithSplitBySeverity(enabled bool) option {
+	return func(o *options) {
+		o.split = enabled
+	}
+}
+
+// WithMinCves fail the update when fewer than n cves are collected
+func WithMinCves(n int) option {
+	return func(o *options) {
+		o.minCves = n
+	}
+}
+
+// WithCollectorInfo set whether cves record the collector version and collection time, default disabled
+// since the time stamp change every cve file on each update
+func WithCollectorInfo(enabled bool) option {
+	return func(o *options) {
+		o.info = enabled
+	}
+}
+
+// WithCvssDetails set whether cves record their cvss version, vector components and exploitability tags,
+// default disabled
+func WithCvssDetails(enabled bool) option {
+	return func(o *options) {
+		o.cvss = enabled
+	}
+}
+
 func (u Updater) Update() error {
+	if err := cve.ValidateOutputFormat(u.format); err != nil {
+		return err
+	}
+	if u.split && u.format != cve.FormatJSON {
+		return fmt.Errorf("split by severity output only support the %s format", cve.FormatJSON)
+	}
 	log.Println("Fetching k8s vulndb cve data...")
-	vulnDB, err := cve.Collect()
+	var overrides cve.Overrides
+	if len(u.overrides) > 0 {
+		var err error
+		if overrides, err = cve.LoadOverrides(u.overrides); err != nil {
+			return fmt.Errorf("failed to load overrides: %w", err)
+		}
+	}
+	var nvd cve.NVDIndex
+	if len(u.nvdFeeds) > 0 {
+		var err error
+		if nvd, err = cve.LoadNVDFeed(u.nvdFeeds...); err != nil {
+			return fmt.Errorf("failed to load nvd feeds: %w", err)
+		}
+	}
+	stats := &cve.CollectStats{}
+	vulnDB, err := cve.Collect(cve.WithOverrides(overrides), cve.WithNVD(nvd), cve.WithMinCves(u.minCves),
+		cve.WithCollectorInfo(u.info), cve.WithCvssDetails(u.cvss), cve.WithStats(stats))
+	log.Print(cve.Summarize(stats.CollectedCves, stats.ValidationIssues))
 	if err != nil {
 		return err
 	}
@@ -58,27 +137,18 @@ func (u Updater) Update() error {
 		return fmt.Errorf("no vulndb cve-list data to publish")
 	}
 	fp := filepath.Join(u.k8sdDir, u.cveFolder)
-	log.Printf("Remove k8s vulndb cves directory %s", fp)
-	if err := os.RemoveAll(fp); err != nil {
-		return fmt.Errorf("failed to remove k8s vulndb cves directory: %w", err)
+	// a previous dump is missing on the first update, nothing disappeared then
+	if previous, err := cve.LoadDir(fp); err == nil {
+		for _, d := range cve.DisappearedCves(previous, vulnDB, stats) {
+			log.Printf("cve %s disappeared from the k8s vulndb: %s %s", d.CveID, d.Reason, d.Detail)
+		}
 	}
-	if err := os.MkdirAll(fp, 0755); err != nil {
-		return fmt.Errorf("mkdir error: %w", err)
+	log.Printf("Replace k8s vulndb cves directory %s", fp)
+	if u.split {
+		return cve.WriteBySeverity(vulnDB, fp)
 	}
-	for _, cve := range vulnDB.Cves {
-		data, err := json.Marshal(cve)
-		if err != nil {
-			return err
-		}
-		var prettyJSON bytes.Buffer
-		if err := json.Indent(&prettyJSON, data, "", "\t"); err != nil {
-			return fmt.Errorf("failed ro format json: %w", err)
-		}
-		filePath := filepath.Join(fp, fmt.Sprintf("%s.json", cve.ID))
-		if err = os.WriteFile(filePath, prettyJSON.Bytes(), 0644); err != nil {
-			return xerrors.Errorf("write error: %w", err)
-		}
+	if err := cve.WriteToDir(vulnDB, fp, u.format); err != nil {
+		return xerrors.Errorf("failed to write k8s vulndb cves directory: %w", err)
 	}
-
 	return nil
 }
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// githubIssueRegex match kubernetes org github issue and pull request links
+	githubIssueRegex = regexp.MustCompile(`https?://github\.com/kubernetes(?:-sigs)?/[\w.-]+/(?:issues|pull)/\d+`)
+	// kepRegex match KEP links of the kubernetes enhancements repo
+	kepRegex = regexp.MustCompile(`https?://github\.com/kubernetes/enhancements/(?:tree|blob)/[\w.-]+/keps/[\w./-]*[\w-]`)
+)
+
+// ExtractAdvisoryRefs return the sorted github issue, pull request and KEP links found in texts, nil when there are none
+func ExtractAdvisoryRefs(texts ...string) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	for _, text := range texts {
+		for _, re := range []*regexp.Regexp{githubIssueRegex, kepRegex} {
+			for _, m := range re.FindAllString(text, -1) {
+				m = strings.Replace(m, "http://", "https://", 1)
+				if !seen[m] {
+					seen[m] = true
+					refs = append(refs, m)
+				}
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractAdvisoryRefs(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{name: "issue in text", texts: []string{"tracked in https://github.com/kubernetes/kubernetes/issues/118690."},
+			want: []string{"https://github.com/kubernetes/kubernetes/issues/118690"}},
+		{name: "kep and pull request", texts: []string{
+			"see https://github.com/kubernetes/enhancements/tree/master/keps/sig-auth/3299-kms-v2-improvements/)",
+			"http://github.com/kubernetes-sigs/secrets-store-csi-driver/pull/1200",
+		}, want: []string{
+			"https://github.com/kubernetes-sigs/secrets-store-csi-driver/pull/1200",
+			"https://github.com/kubernetes/enhancements/tree/master/keps/sig-auth/3299-kms-v2-improvements",
+		}},
+		{name: "dedup", texts: []string{"https://github.com/kubernetes/kubernetes/issues/1001", "https://github.com/kubernetes/kubernetes/issues/1001"},
+			want: []string{"https://github.com/kubernetes/kubernetes/issues/1001"}},
+		{name: "no refs", texts: []string{"https://www.cve.org/cverecord?id=CVE-2023-1001", "https://github.com/other/repo/issues/1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExtractAdvisoryRefs(tt.texts...))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// componentCvssHints list the cvss metrics typical of each component attack surface, e.g. a kubelet issue is
+// usually exploited locally from a pod escaping to the node while an apiserver one by a remote authenticated user
+var componentCvssHints = map[string][]string{
+	"apiserver":                {"AV:N", "PR:L"},
+	"controller-manager":       {"AV:N", "PR:H"},
+	"kube-scheduler":           {"AV:N", "PR:H"},
+	"kubelet":                  {"AV:L", "S:C"},
+	"kube-proxy":               {"AV:A"},
+	"kubectl":                  {"UI:R"},
+	"secrets-store-csi-driver": {"AV:L", "C:H"},
+}
+
+// ComponentCandidates return the components named by the descriptions with their number of mentions,
+// aliases of the same component (e.g. api server and kube-apiserver) add up
+func ComponentCandidates(descriptions ...string) map[string]int {
+	candidates := make(map[string]int)
+	for _, d := range descriptions {
+		d = strings.ToLower(d)
+		for key, value := range UpstreamRepoName {
+			if key == "kubernetes" {
+				continue
+			}
+			if c := strings.Count(d, key); c > 0 {
+				candidates[value] += c
+			}
+		}
+	}
+	return candidates
+}
+
+// GetComponentFromDescriptionAndCvss return the component most mentioned by the descriptions, components
+// mentioned as often are told apart by how many of their typical cvss metrics the vector has, then by name
+func GetComponentFromDescriptionAndCvss(vector string, descriptions ...string) string {
+	candidates := make(map[string]float64)
+	for name, count := range ComponentCandidates(descriptions...) {
+		candidates[name] = float64(count)
+	}
+	// a single kubectl mention in a "kubectl version" instruction does not name the affected component
+	for _, d := range descriptions {
+		if candidates["kubectl"] == 1 && strings.Contains(strings.ToLower(d), "kubectl version") {
+			delete(candidates, "kubectl")
+		}
+	}
+	return rankedComponent(candidates, vector)
+}
+
+// keyword weights of ComponentScores
+const (
+	// leadWeight is added to a mention in the first sentence of a description, where the affected component is
+	// usually introduced
+	leadWeight = 1.0
+	// cueWeight is added to a mention within cueDistance words after a vulnerability cue, e.g. "issue in kubelet"
+	cueWeight   = 2.0
+	cueDistance = 3
+)
+
+// vulnerabilityCues are the words introducing the affected component
+var vulnerabilityCues = map[string]bool{"in": true, "affecting": true, "affects": true}
+
+// instructionCues are the words introducing a command to run, e.g. "run kubectl version", a mention right after
+// one weighs nothing as it does not name the affected component
+var instructionCues = map[string]bool{"run": true, "use": true}
+
+// ComponentScores return the components named by the descriptions weighted by their mentions, a mention
+// count 1 plus leadWeight in a first sentence plus cueWeight close after a vulnerability cue, so the primary
+// component outweigh the ones merely involved (e.g. "an issue in kube-proxy ... pods on the kubelet ... kubelet").
+// mentions after an instruction cue are not scored
+func ComponentScores(descriptions ...string) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, d := range descriptions {
+		d = strings.ToLower(d)
+		lead := len(d)
+		for _, end := range []string{". ", "\n"} {
+			if i := strings.Index(d, end); i >= 0 && i < lead {
+				lead = i
+			}
+		}
+		for key, value := range UpstreamRepoName {
+			if key == "kubernetes" {
+				continue
+			}
+			for offset := 0; ; {
+				i := strings.Index(d[offset:], key)
+				if i < 0 {
+					break
+				}
+				pos := offset + i
+				offset = pos + len(key)
+				if cuedMention(d[:pos], instructionCues, 1) {
+					continue
+				}
+				weight := 1.0
+				if pos < lead {
+					weight += leadWeight
+				}
+				if cuedMention(d[:pos], vulnerabilityCues, cueDistance) {
+					weight += cueWeight
+				}
+				scores[value] += weight
+			}
+		}
+	}
+	return scores
+}
+
+// cuedMention check if one of the last distance words before a mention is one of cues, punctuation is not a word
+func cuedMention(before string, cues map[string]bool, distance int) bool {
+	words := strings.FieldsFunc(before, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:()`'\"", r)
+	})
+	for i := len(words) - 1; i >= 0 && i >= len(words)-distance; i-- {
+		if cues[words[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// GetComponentByKeywordWeight return the component with the highest ComponentScores, components scored the
+// same are told apart by how many of their typical cvss metrics the vector has, then by name
+func GetComponentByKeywordWeight(vector string, descriptions ...string) string {
+	return rankedComponent(ComponentScores(descriptions...), vector)
+}
+
+// rankedComponent return the highest scored candidate, ties are broken by cvss hints then by name
+func rankedComponent(candidates map[string]float64, vector string) string {
+	metrics := make(map[string]bool)
+	for _, m := range strings.Split(vector, "/") {
+		metrics[m] = true
+	}
+	cvssScore := func(component string) int {
+		var score int
+		for _, hint := range componentCvssHints[component] {
+			if metrics[hint] {
+				score++
+			}
+		}
+		return score
+	}
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if candidates[names[i]] != candidates[names[j]] {
+			return candidates[names[i]] > candidates[names[j]]
+		}
+		if si, sj := cvssScore(names[i]), cvssScore(names[j]); si != sj {
+			return si > sj
+		}
+		return names[i] < names[j]
+	})
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetComponentFromDescriptionAndCvss(t *testing.T) {
+	tests := []struct {
+		name        string
+		vector      string
+		description string
+		want        string
+	}{
+		{name: "most mentioned component", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N",
+			description: "kubelet pods can bypass the kubelet seccomp enforcement through the kube-apiserver", want: "kubelet"},
+		{name: "aliases add up", vector: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:C/C:H/I:N/A:N",
+			description: "the api server and kube-apiserver audit log expose tokens the kubelet use", want: "apiserver"},
+		{name: "local scope change pick the node component", vector: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H",
+			description: "a pod created through the kube-apiserver can escape to the host via the kubelet", want: "kubelet"},
+		{name: "remote authenticated pick the control plane", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N",
+			description: "a pod created through the kube-apiserver can escape to the host via the kubelet", want: "apiserver"},
+		{name: "no vector tie broken by name", description: "a pod created through the kube-apiserver can escape to the host via the kubelet", want: "apiserver"},
+		{name: "kubectl version instruction", description: "run kubectl version to check the kubelet", want: "kubelet"},
+		{name: "only kubectl version instruction", description: "run kubectl version to check your cluster", want: ""},
+		{name: "no component", description: "a security issue was discovered in Kubernetes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetComponentFromDescriptionAndCvss(tt.vector, tt.description))
+		})
+	}
+}
+
+func TestGetComponentByKeywordWeight(t *testing.T) {
+	tests := []struct {
+		name        string
+		vector      string
+		description string
+		want        string
+	}{
+		{name: "primary component in first sentence", description: "A security issue was discovered in kube-proxy where a pod can redirect traffic. " +
+			"Nodes whose kubelet runs hostNetwork pods are affected, the kubelet itself is not vulnerable.", want: "kube-proxy"},
+		{name: "cued component", description: "pods scheduled by the kube-scheduler can read node secrets through a vulnerability in the kubelet",
+			want: "kubelet"},
+		{name: "single component", description: "an issue was found in the api server", want: "apiserver"},
+		{name: "tie broken by cvss", vector: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H",
+			description: "the kube-apiserver and kubelet are affected", want: "kubelet"},
+		{name: "kubectl version instruction", description: "run kubectl version to check the kubelet", want: "kubelet"},
+		{name: "only kubectl version instruction", description: "Kubernetes version (use `kubectl version`): 1.24", want: ""},
+		{name: "kubectl affected next to an instruction", description: "an issue in kubectl cp allow writing outside the destination. " +
+			"run kubectl version to check your client", want: "kubectl"},
+		{name: "no component", description: "a security issue was discovered in Kubernetes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetComponentByKeywordWeight(tt.vector, tt.description))
+		})
+	}
+}
+
+func TestComponentScores(t *testing.T) {
+	assert.Equal(t, map[string]float64{"kube-proxy": 4, "kubelet": 2},
+		ComponentScores("An issue in kube-proxy was found. kubelet and kubelet are not affected."))
+	// an instruction mention is not scored
+	assert.Equal(t, map[string]float64{"kubelet": 2},
+		ComponentScores("The kubelet leak tokens. Run kubectl version to check your cluster."))
+}
+
+func TestGetComponentFromDescriptionAndffected(t *testing.T) {
+	// mention count, keyword weighting is opt-in through GetComponentByKeywordWeight
+	description := "A security issue was discovered in kube-proxy. Nodes whose kubelet runs hostNetwork pods are affected, the kubelet is not."
+	assert.Equal(t, "kubelet", GetComponentFromDescriptionAndffected(description))
+	assert.Equal(t, "kube-proxy", GetComponentByKeywordWeight("", description))
+}
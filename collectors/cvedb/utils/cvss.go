@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"strings"
+)
+
+// CvssComponents is the parsed cvss v3 base metrics of a vector, e.g. AttackVector "N" for AV:N
+type CvssComponents struct {
+	AttackVector       string `json:"AV"`
+	AttackComplexity   string `json:"AC"`
+	PrivilegesRequired string `json:"PR"`
+	UserInteraction    string `json:"UI"`
+	Scope              string `json:"S"`
+	Confidentiality    string `json:"C"`
+	Integrity          string `json:"I"`
+	Availability       string `json:"A"`
+}
+
+// defaultCvssComponents is used for base metrics missing from a vector, assuming the worst case
+var defaultCvssComponents = CvssComponents{
+	AttackVector:       "N",
+	AttackComplexity:   "L",
+	PrivilegesRequired: "N",
+	UserInteraction:    "N",
+	Scope:              "U",
+	Confidentiality:    "H",
+	Integrity:          "H",
+	Availability:       "H",
+}
+
+// v2 metrics values mapped to their closest v3 value, v2 vectors have no version prefix
+var (
+	v2Authentication = map[string]string{"N": "N", "S": "L", "M": "H"}
+	v2Impact         = map[string]string{"N": "N", "P": "L", "C": "H"}
+)
+
+// ParseCvssVector parse the base metrics of a cvss v2, v3.x or v4.0 vector into v3 components, metrics missing
+// from the vector default to their worst case value. v2 authentication is read as privileges required and
+// v4.0 vulnerable system impacts as the impacts, v2 and v4.0 have no scope and v2 no user interaction
+func ParseCvssVector(vector string) CvssComponents {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if kv := strings.SplitN(part, ":", 2); len(kv) == 2 {
+			metrics[kv[0]] = kv[1]
+		}
+	}
+	components := defaultCvssComponents
+	set := func(field *string, value string) {
+		if len(value) > 0 {
+			*field = value
+		}
+	}
+	set(&components.AttackVector, metrics["AV"])
+	set(&components.AttackComplexity, metrics["AC"])
+	set(&components.PrivilegesRequired, metrics["PR"])
+	set(&components.UserInteraction, metrics["UI"])
+	switch {
+	case strings.HasPrefix(metrics["CVSS"], "3"):
+		set(&components.Scope, metrics["S"])
+		set(&components.Confidentiality, metrics["C"])
+		set(&components.Integrity, metrics["I"])
+		set(&components.Availability, metrics["A"])
+	case strings.HasPrefix(metrics["CVSS"], "4"):
+		set(&components.Confidentiality, metrics["VC"])
+		set(&components.Integrity, metrics["VI"])
+		set(&components.Availability, metrics["VA"])
+		// v4.0 user interaction is None, Passive or Active
+		if ui := metrics["UI"]; ui == "P" || ui == "A" {
+			components.UserInteraction = "R"
+		}
+	default:
+		set(&components.PrivilegesRequired, v2Authentication[metrics["Au"]])
+		set(&components.Confidentiality, v2Impact[metrics["C"]])
+		set(&components.Integrity, v2Impact[metrics["I"]])
+		set(&components.Availability, v2Impact[metrics["A"]])
+		// v2 access complexity has a medium value v3 folds into high
+		if metrics["AC"] == "M" {
+			components.AttackComplexity = "H"
+		}
+	}
+	return components
+}
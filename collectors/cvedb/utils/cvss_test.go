@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCvssVector(t *testing.T) {
+	tests := []struct {
+		name   string
+		vector string
+		want   CvssComponents
+	}{
+		{name: "full v3.1", vector: "CVSS:3.1/AV:L/AC:H/PR:L/UI:R/S:C/C:H/I:L/A:N",
+			want: CvssComponents{AttackVector: "L", AttackComplexity: "H", PrivilegesRequired: "L", UserInteraction: "R", Scope: "C", Confidentiality: "H", Integrity: "L", Availability: "N"}},
+		{name: "v3.0 missing metrics", vector: "CVSS:3.0/AV:A/AC:L/C:L",
+			want: CvssComponents{AttackVector: "A", AttackComplexity: "L", PrivilegesRequired: "N", UserInteraction: "N", Scope: "U", Confidentiality: "L", Integrity: "H", Availability: "H"}},
+		{name: "v2", vector: "AV:N/AC:M/Au:S/C:P/I:N/A:C",
+			want: CvssComponents{AttackVector: "N", AttackComplexity: "H", PrivilegesRequired: "L", UserInteraction: "N", Scope: "U", Confidentiality: "L", Integrity: "N", Availability: "H"}},
+		{name: "v4.0", vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:H/UI:P/VC:H/VI:L/VA:N/SC:N/SI:N/SA:N",
+			want: CvssComponents{AttackVector: "N", AttackComplexity: "L", PrivilegesRequired: "H", UserInteraction: "R", Scope: "U", Confidentiality: "H", Integrity: "L", Availability: "N"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseCvssVector(tt.vector))
+		})
+	}
+}
+
+func TestCvssVectorToScore(t *testing.T) {
+	severity, score, components := CvssVectorToScore("CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H")
+	assert.Equal(t, "High", severity)
+	assert.Equal(t, 8.8, score)
+	assert.Equal(t, CvssComponents{AttackVector: "N", AttackComplexity: "L", PrivilegesRequired: "L", UserInteraction: "N", Scope: "U", Confidentiality: "H", Integrity: "H", Availability: "H"}, components)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+)
+
+// ExploitabilityTagTable map cvss vector metrics (e.g. AV:N) to the exploitability tag they imply
+type ExploitabilityTagTable map[string]string
+
+// DefaultExploitabilityTagTable tag the cvss v2, v3.x and v4.0 metrics making a cve easier to exploit
+var DefaultExploitabilityTagTable = ExploitabilityTagTable{
+	"AV:N": "network-exploitable",
+	"AV:A": "adjacent-exploitable",
+	"AV:L": "local-exploitable",
+	"AV:P": "physical-access",
+	"AC:L": "low-complexity",
+	"PR:N": "no-auth",
+	"Au:N": "no-auth",
+	"UI:N": "no-user-interaction",
+	"S:C":  "scope-changed",
+}
+
+// Tags return the tags of vector metrics in vector order, a tag implied by several metrics is returned once
+func (t ExploitabilityTagTable) Tags(vector string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, metric := range strings.Split(vector, "/") {
+		if tag, ok := t[metric]; ok && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ExploitabilityTags return the DefaultExploitabilityTagTable tags of vector, e.g. network-exploitable and no-auth
+// for AV:N/PR:N
+func ExploitabilityTags(vector string) []string {
+	return DefaultExploitabilityTagTable.Tags(vector)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExploitabilityTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		vector string
+		want   []string
+	}{
+		{name: "network no auth", vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+			want: []string{"network-exploitable", "low-complexity", "no-auth", "no-user-interaction"}},
+		{name: "local scope changed", vector: "CVSS:3.1/AV:L/AC:H/PR:L/UI:R/S:C/C:H/I:L/A:N",
+			want: []string{"local-exploitable", "scope-changed"}},
+		{name: "v2", vector: "AV:N/AC:M/Au:N/C:P/I:N/A:C",
+			want: []string{"network-exploitable", "no-auth"}},
+		{name: "v4.0", vector: "CVSS:4.0/AV:A/AC:L/AT:N/PR:N/UI:P/VC:H/VI:L/VA:N/SC:N/SI:N/SA:N",
+			want: []string{"adjacent-exploitable", "low-complexity", "no-auth"}},
+		{name: "empty", vector: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExploitabilityTags(tt.vector))
+		})
+	}
+}
+
+func TestExploitabilityTagTable(t *testing.T) {
+	table := ExploitabilityTagTable{"AV:N": "remote", "PR:N": "remote"}
+	assert.Equal(t, []string{"remote"}, table.Tags("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"))
+}
@@ -4,10 +4,11 @@ import (
 	"fmt"
 
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	version "github.com/aquasecurity/go-pep440-version"
-	"github.com/goark/go-cvss/v3/metric"
 )
 
 var (
@@ -25,10 +26,23 @@ var (
 		"kube-scheduler":           "kube-scheduler",
 		"kube-proxy":               "kube-proxy",
 		"api server":               "apiserver",
+		"kube-api-server":          "apiserver",
 		"secrets-store-csi-driver": "secrets-store-csi-driver",
 	}
+
+	// upstreamRepoByNormalizedName is UpstreamRepoName keyed by normalized component names, built once
+	upstreamRepoByNormalizedName = normalizedKeys(UpstreamRepoName)
 )
 
+// normalizedKeys return a copy of m keyed by NormalizeComponentName of its keys
+func normalizedKeys(m map[string]string) map[string]string {
+	normalized := make(map[string]string, len(m))
+	for key, val := range m {
+		normalized[NormalizeComponentName(key)] = val
+	}
+	return normalized
+}
+
 func TrimString(version string, trimValues []string) string {
 	for _, v := range trimValues {
 		version = strings.ReplaceAll(version, v, "")
@@ -114,15 +128,83 @@ func UpdatedLine(versionParts []string, sign string) string {
 	return ""
 }
 
-func CvssVectorToScore(vector string) (string, float64) {
-	bm, err := metric.NewBase().Decode(vector) //CVE-2020-1472: ZeroLogon
+// CvssVectorToScore return the vector severity, base score and parsed components
+func CvssVectorToScore(vector string) (string, float64, CvssComponents) {
+	severity, score := CvssVectorToSeverity(vector, DefaultSeverityTable)
+	return severity, score, ParseCvssVector(vector)
+}
+
+// throughRangeRegex match an inclusive range worded "1.20 through 1.22", "1.20 thru 1.22" or "1.20 to 1.22"
+var throughRangeRegex = regexp.MustCompile(`(?i)^v?(\d+\.\d+(?:\.\d+)?)\s+(?:through|thru|to)\s+v?(\d+\.\d+(?:\.\d+)?)$`)
+
+// ThroughRange return the introduced, inclusive last affected and fixed bounds of a "1.20 through 1.22" worded
+// range. a two-segment introduced bound is its line first release (1.20.0) and a two-segment upper bound cover
+// its whole line, fixed by the next line first release (1.23.0) instead of a last affected version
+func ThroughRange(value string) (string, string, string, bool) {
+	m := throughRangeRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return "", "", "", false
+	}
+	from := m[1]
+	if strings.Count(from, ".") == 1 {
+		from = from + ".0"
+	}
+	lastAffected, fixed := lineUpperBound(m[2])
+	return from, lastAffected, fixed, true
+}
+
+// lineUpperBound return an inclusive upper bound as a last affected version, or for a two-segment line
+// (1.22) as the fixed first release of the next line (1.23.0)
+func lineUpperBound(upper string) (string, string) {
+	parts := strings.Split(upper, ".")
+	if len(parts) != 2 {
+		return upper, ""
+	}
+	minor, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return "", 0.0
+		return upper, ""
+	}
+	return "", fmt.Sprintf("%s.%d.0", parts[0], minor+1)
+}
+
+// trailingRangeRegex match a range open on one side worded "1.24.0 and earlier" or "1.24.0 and later"
+var trailingRangeRegex = regexp.MustCompile(`(?i)^v?(\d+\.\d+(?:\.\d+)?)\s+and\s+(earlier|later)$`)
+
+// TrailingRange return the introduced, inclusive last affected and fixed bounds of a range open on one side.
+// "1.24.0 and earlier" is open below, introduced from "0", with a two-segment version covering its whole line
+// (fixed 1.25.0) like the ThroughRange upper bound. "1.24.0 and later" is open above, introduced only, with a
+// two-segment version starting at its line first release (1.24.0)
+func TrailingRange(value string) (string, string, string, bool) {
+	m := trailingRangeRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return "", "", "", false
+	}
+	if strings.EqualFold(m[2], "earlier") {
+		lastAffected, fixed := lineUpperBound(m[1])
+		return "0", lastAffected, fixed, true
 	}
-	return bm.Severity().String(), bm.Score()
+	from := m[1]
+	if strings.Count(from, ".") == 1 {
+		from = from + ".0"
+	}
+	return from, "", "", true
 }
 
+// ExtractVersions return the introduced and last affected bounds of a mitre version, see ExtractRange
 func ExtractVersions(lessOps, origVersion string, ftype string) (string, string) {
+	from, to, _ := ExtractRange(lessOps, origVersion, ftype)
+	return from, to
+}
+
+// ExtractRange return the introduced, last affected and fixed bounds of a mitre version, fixed is only set
+// by worded ranges ending on a whole line, e.g. "1.20 through 1.22" or "1.24 and earlier"
+func ExtractRange(lessOps, origVersion string, ftype string) (string, string, string) {
+	if from, lastAffected, fixed, ok := ThroughRange(origVersion); ok && len(lessOps) == 0 {
+		return from, lastAffected, fixed
+	}
+	if from, lastAffected, fixed, ok := TrailingRange(origVersion); ok && len(lessOps) == 0 {
+		return from, lastAffected, fixed
+	}
 	var from, to string
 	if (ftype == "lessThen" || ftype == "lessThenEqual") && len(lessOps) > 0 {
 		from = origVersion
@@ -137,7 +219,7 @@ func ExtractVersions(lessOps, origVersion string, ftype string) (string, string)
 		if ftype == "lessThenEqual" {
 			to = strings.TrimSpace(lessOps)
 		}
-		return from, to
+		return from, to, ""
 	}
 
 	validVersion := make([]string, 0)
@@ -155,12 +237,12 @@ func ExtractVersions(lessOps, origVersion string, ftype string) (string, string)
 	}
 	if len(validVersion) == 1 {
 		from = strings.TrimSpace(validVersion[0])
-		return from, to
+		return from, to, ""
 	}
 	if len(validVersion) == 2 {
-		return strings.TrimSpace(validVersion[0]), strings.TrimSpace(validVersion[1])
+		return strings.TrimSpace(validVersion[0]), strings.TrimSpace(validVersion[1]), ""
 	}
-	return from, ""
+	return from, "", ""
 }
 
 func FindVersion(versionString string) string {
@@ -194,10 +276,21 @@ func GetMultiIDs(id string) []string {
 	return []string{id}
 }
 
+// NormalizeComponentName lowercase and trim component name and collapse any run of spaces, underscores
+// and dashes into a single dash, so spelling variants like "Kube API Server" and "kube_api_server" compare equal
+func NormalizeComponentName(component string) string {
+	parts := strings.FieldsFunc(strings.ToLower(component), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '_' || r == '-'
+	})
+	return strings.Join(parts, "-")
+}
+
 func UpstreamOrgByName(component string) string {
+	name := NormalizeComponentName(component)
+	repo := UpstreamRepoByName(component)
 	for key, components := range UpstreamOrgName {
 		for _, c := range strings.Split(components, ",") {
-			if strings.TrimSpace(c) == strings.ToLower(component) {
+			if c = strings.TrimSpace(c); c == name || c == repo {
 				return key
 			}
 		}
@@ -206,38 +299,12 @@ func UpstreamOrgByName(component string) string {
 }
 
 func UpstreamRepoByName(component string) string {
-	if val, ok := UpstreamRepoName[component]; ok {
-		return val
+	if repo, ok := upstreamRepoByNormalizedName[NormalizeComponentName(component)]; ok {
+		return repo
 	}
 	return component
 }
 
 func GetComponentFromDescriptionAndffected(descriptions ...string) string {
-	var compName string
-	var compCounter int
-	var kubeCtlVersionFound bool
-	for _, d := range descriptions {
-		for key, value := range UpstreamRepoName {
-			if key == "kubernetes" {
-				continue
-			}
-			if strings.Contains(strings.ToLower(d), key) {
-				c := strings.Count(strings.ToLower(d), key)
-				if value == compName {
-					compCounter = compCounter + c
-				}
-				if strings.Contains(strings.ToLower(d), "kubectl version") {
-					kubeCtlVersionFound = true
-				}
-				if c > compCounter {
-					compCounter = c
-					compName = value
-				}
-			}
-		}
-	}
-	if kubeCtlVersionFound && compName == "kubectl" && compCounter == 1 {
-		compName = ""
-	}
-	return compName
+	return GetComponentFromDescriptionAndCvss("", descriptions...)
 }
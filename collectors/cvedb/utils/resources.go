@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+)
+
+var (
+	// KindGroupVersion map well known built-in kinds to their group/version
+	KindGroupVersion = map[string]string{
+		"Pod":                            "v1",
+		"Service":                        "v1",
+		"Secret":                         "v1",
+		"ConfigMap":                      "v1",
+		"Node":                           "v1",
+		"Namespace":                      "v1",
+		"Endpoints":                      "v1",
+		"ServiceAccount":                 "v1",
+		"PersistentVolume":               "v1",
+		"PersistentVolumeClaim":          "v1",
+		"Deployment":                     "apps/v1",
+		"DaemonSet":                      "apps/v1",
+		"StatefulSet":                    "apps/v1",
+		"ReplicaSet":                     "apps/v1",
+		"Job":                            "batch/v1",
+		"CronJob":                        "batch/v1",
+		"Ingress":                        "networking.k8s.io/v1",
+		"NetworkPolicy":                  "networking.k8s.io/v1",
+		"EndpointSlice":                  "discovery.k8s.io/v1",
+		"Role":                           "rbac.authorization.k8s.io/v1",
+		"ClusterRole":                    "rbac.authorization.k8s.io/v1",
+		"RoleBinding":                    "rbac.authorization.k8s.io/v1",
+		"ClusterRoleBinding":             "rbac.authorization.k8s.io/v1",
+		"CustomResourceDefinition":       "apiextensions.k8s.io/v1",
+		"ValidatingWebhookConfiguration": "admissionregistration.k8s.io/v1",
+		"MutatingWebhookConfiguration":   "admissionregistration.k8s.io/v1",
+		"StorageClass":                   "storage.k8s.io/v1",
+		"CSIDriver":                      "storage.k8s.io/v1",
+		"VolumeAttachment":               "storage.k8s.io/v1",
+	}
+
+	gvkRegex  = regexp.MustCompile(`\b((?:[a-z0-9-]+\.)*[a-z0-9-]+/)?(v\d+(?:(?:alpha|beta)\d+)?)/([A-Z][A-Za-z]+)\b`)
+	kindRegex = regexp.MustCompile(`\b[A-Z][A-Za-z]+\b`)
+)
+
+// ExtractResources return the group/version/kind (e.g. apps/v1/Deployment) of api resources named by text, sorted.
+// explicit gvk mentions are kept as is and well known kind names (case sensitive) are mapped with KindGroupVersion
+func ExtractResources(text string) []string {
+	found := make(map[string]bool)
+	for _, m := range gvkRegex.FindAllStringSubmatch(text, -1) {
+		found[m[1]+m[2]+"/"+m[3]] = true
+	}
+	for _, kind := range kindRegex.FindAllString(gvkRegex.ReplaceAllString(text, ""), -1) {
+		if gv, ok := KindGroupVersion[kind]; ok {
+			found[gv+"/"+kind] = true
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	resources := make([]string, 0, len(found))
+	for r := range found {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	return resources
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractResources(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "known kind", text: "A user able to create an EndpointSlice can redirect traffic", want: []string{"discovery.k8s.io/v1/EndpointSlice"}},
+		{name: "explicit gvk", text: "updating apps/v1/Deployment or v1beta1/Widget objects", want: []string{"apps/v1/Deployment", "v1beta1/Widget"}},
+		{name: "dedup", text: "a Secret mounted as a v1/Secret volume", want: []string{"v1/Secret"}},
+		{name: "lowercase prose", text: "allows pods to bypass the seccomp profile enforcement", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExtractResources(tt.text))
+		})
+	}
+}
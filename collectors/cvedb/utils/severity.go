@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"github.com/goark/go-cvss/v3/metric"
+)
+
+// SeverityBand map cvss scores from MinScore (inclusive) up to the next band MinScore to Label
+type SeverityBand struct {
+	MinScore float64
+	Label    string
+}
+
+// SeverityTable is a list of severity bands, score falls into the band with the highest MinScore not above it
+type SeverityTable []SeverityBand
+
+// DefaultSeverityTable is the standard cvss v3 qualitative severity rating scale
+var DefaultSeverityTable = SeverityTable{
+	{MinScore: 0, Label: "None"},
+	{MinScore: 0.1, Label: "Low"},
+	{MinScore: 4.0, Label: "Medium"},
+	{MinScore: 7.0, Label: "High"},
+	{MinScore: 9.0, Label: "Critical"},
+}
+
+// Severity return the label of the band score falls into, empty when score is below every band
+func (t SeverityTable) Severity(score float64) string {
+	var label string
+	best := -1.0
+	// later bands win over earlier ones with the same min score
+	for _, b := range t {
+		if score >= b.MinScore && b.MinScore >= best {
+			best, label = b.MinScore, b.Label
+		}
+	}
+	return label
+}
+
+// CvssVectorToSeverity decode vector and map its base score to a severity using table
+func CvssVectorToSeverity(vector string, table SeverityTable) (string, float64) {
+	bm, err := metric.NewBase().Decode(vector)
+	if err != nil {
+		return "", 0.0
+	}
+	score := bm.Score()
+	return table.Severity(score), score
+}
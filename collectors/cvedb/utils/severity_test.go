@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCvssVectorToSeverity(t *testing.T) {
+	redHat := SeverityTable{
+		{MinScore: 0.1, Label: "Low"},
+		{MinScore: 4.0, Label: "Moderate"},
+		{MinScore: 7.0, Label: "Important"},
+		{MinScore: 9.0, Label: "Critical"},
+	}
+	tests := []struct {
+		name         string
+		vector       string
+		table        SeverityTable
+		wantSeverity string
+		wantScore    float64
+	}{
+		{name: "default low", vector: "CVSS:3.1/AV:L/AC:L/PR:H/UI:N/S:U/C:L/I:L/A:N", table: DefaultSeverityTable, wantSeverity: "Low", wantScore: 3.4},
+		{name: "default high", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", table: DefaultSeverityTable, wantSeverity: "High", wantScore: 8.8},
+		{name: "default none", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:N/I:N/A:N", table: DefaultSeverityTable, wantSeverity: "None", wantScore: 0},
+		{name: "red hat moderate", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:L/I:L/A:N", table: redHat, wantSeverity: "Moderate", wantScore: 5.4},
+		{name: "red hat important", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", table: redHat, wantSeverity: "Important", wantScore: 8.8},
+		{name: "red hat below every band", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:N/I:N/A:N", table: redHat, wantSeverity: "", wantScore: 0},
+		{name: "unordered table", vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", table: SeverityTable{redHat[3], redHat[1], redHat[2], redHat[0]}, wantSeverity: "Important", wantScore: 8.8},
+		{name: "invalid vector", vector: "CVSS:3.1/AV:N", table: redHat, wantSeverity: "", wantScore: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, score := CvssVectorToSeverity(tt.vector, tt.table)
+			assert.Equal(t, tt.wantSeverity, severity)
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
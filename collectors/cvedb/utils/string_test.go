@@ -53,3 +53,70 @@ func TestUpdateLine(t *testing.T) {
 		})
 	}
 }
+
+func TestUpstreamRepoByName(t *testing.T) {
+	tests := []struct {
+		name      string
+		component string
+		wantRepo  string
+		wantOrg   string
+	}{
+		{name: "spaced and capitalized", component: "Kube API Server", wantRepo: "apiserver", wantOrg: "k8s.io"},
+		{name: "dashed", component: "kube-apiserver", wantRepo: "apiserver", wantOrg: "k8s.io"},
+		{name: "underscored", component: "kube_apiserver", wantRepo: "apiserver", wantOrg: "k8s.io"},
+		{name: "padded with extra separators", component: " Kube--Proxy ", wantRepo: "kube-proxy", wantOrg: "k8s.io"},
+		{name: "unknown component", component: "etcd", wantRepo: "etcd", wantOrg: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRepo, UpstreamRepoByName(tt.component))
+			assert.Equal(t, tt.wantOrg, UpstreamOrgByName(tt.component))
+		})
+	}
+}
+
+func TestExtractRangeTrailing(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		wantFrom  string
+		wantTo    string
+		wantFixed string
+	}{
+		{name: "and earlier", version: "1.24.0 and earlier", wantFrom: "0", wantTo: "1.24.0"},
+		{name: "and later", version: "v1.24.0 and later", wantFrom: "1.24.0"},
+		{name: "two-segment and later", version: "1.24 And Later", wantFrom: "1.24.0"},
+		{name: "two-segment and earlier", version: "1.24 and earlier", wantFrom: "0", wantFixed: "1.25.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, fixed := ExtractRange("", tt.version, "")
+			assert.Equal(t, tt.wantFrom, from)
+			assert.Equal(t, tt.wantTo, to)
+			assert.Equal(t, tt.wantFixed, fixed)
+		})
+	}
+}
+
+func TestExtractRangeThrough(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		wantFrom  string
+		wantTo    string
+		wantFixed string
+	}{
+		{name: "through", version: "1.20 through 1.22", wantFrom: "1.20.0", wantFixed: "1.23.0"},
+		{name: "thru", version: "v1.20.3 thru v1.22.1", wantFrom: "1.20.3", wantTo: "1.22.1"},
+		{name: "to", version: "1.20 to 1.22", wantFrom: "1.20.0", wantFixed: "1.23.0"},
+		{name: "single version", version: "1.20.3", wantFrom: "1.20.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, fixed := ExtractRange("", tt.version, "")
+			assert.Equal(t, tt.wantFrom, from)
+			assert.Equal(t, tt.wantTo, to)
+			assert.Equal(t, tt.wantFixed, fixed)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	textVersionRegex = regexp.MustCompile(`v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.]+)?)`)
+	fixedInRegex     = regexp.MustCompile(`(?i)fixed in(?: versions?)?:?\s+((?:v?\d+\.\d+\.\d+(?:\s*(?:,|and|or)\s*)?)+)`)
+)
+
+// TextRange is a version range stated in advisory free text
+type TextRange struct {
+	Introduced   string
+	Fixed        string
+	LastAffected string
+}
+
+// ExtractTextRanges extract version ranges from advisory text (e.g. the k8s feed content_text).
+// bullets of an "Affected Versions" section are used when present, otherwise each version listed in a
+// "Fixed Versions" section or a "fixed in 1.24.2, 1.23.8" phrase anchor a range on its minor line
+func ExtractTextRanges(text string) []TextRange {
+	affected, fixed := textSections(text)
+	ranges := make([]TextRange, 0)
+	for _, line := range affected {
+		versions := textVersionRegex.FindAllStringSubmatch(line, -1)
+		switch {
+		case len(versions) >= 2:
+			ranges = append(ranges, TextRange{Introduced: versions[0][1], LastAffected: versions[1][1]})
+		case len(versions) == 1 && strings.Contains(line, "<="):
+			ranges = append(ranges, TextRange{Introduced: "0", LastAffected: versions[0][1]})
+		case len(versions) == 1 && strings.Contains(line, "<"):
+			ranges = append(ranges, TextRange{Introduced: "0", Fixed: versions[0][1]})
+		case len(versions) == 1:
+			ranges = append(ranges, TextRange{Introduced: versions[0][1], LastAffected: versions[0][1]})
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges
+	}
+	fixedVersions := make([]string, 0)
+	for _, line := range fixed {
+		for _, v := range textVersionRegex.FindAllStringSubmatch(line, -1) {
+			fixedVersions = append(fixedVersions, v[1])
+		}
+	}
+	for _, m := range fixedInRegex.FindAllStringSubmatch(text, -1) {
+		for _, v := range textVersionRegex.FindAllStringSubmatch(m[1], -1) {
+			fixedVersions = append(fixedVersions, v[1])
+		}
+	}
+	seen := make(map[string]bool)
+	for _, v := range fixedVersions {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		ranges = append(ranges, TextRange{Introduced: lineStart(v), Fixed: v})
+	}
+	return ranges
+}
+
+// textSections return the bullet lines of the affected and fixed versions sections
+func textSections(text string) ([]string, []string) {
+	affected, fixed := make([]string, 0), make([]string, 0)
+	var current *[]string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(line, "#") && strings.Contains(lower, "affected versions"):
+			current = &affected
+		case strings.HasPrefix(line, "#") && strings.Contains(lower, "fixed versions"):
+			current = &fixed
+		case strings.HasPrefix(line, "#"):
+			current = nil
+		case current != nil && (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "*")):
+			*current = append(*current, line)
+		}
+	}
+	return affected, fixed
+}
+
+// lineStart return the first release of version minor line, or 0 when version is itself a line first release
+func lineStart(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if strings.SplitN(parts[2], "-", 2)[0] == "0" {
+		return "0"
+	}
+	return fmt.Sprintf("%s.%s.0", parts[0], parts[1])
+}
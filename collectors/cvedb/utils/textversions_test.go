@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractTextRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []TextRange
+	}{
+		{name: "fixed in phrase", text: "This issue is fixed in 1.24.2, 1.23.8 and v1.25.0.",
+			want: []TextRange{{Introduced: "1.24.0", Fixed: "1.24.2"}, {Introduced: "1.23.0", Fixed: "1.23.8"}, {Introduced: "0", Fixed: "1.25.0"}}},
+		{name: "affected section", text: "#### Affected Versions\n\n- kubelet v1.27.0 - v1.27.1\n- kubelet <= v1.24.13\n\n#### Fixed Versions\n\n- kubelet v1.27.2\n",
+			want: []TextRange{{Introduced: "1.27.0", LastAffected: "1.27.1"}, {Introduced: "0", LastAffected: "1.24.13"}}},
+		{name: "fixed section", text: "#### Fixed Versions\r\n\r\n- kubelet v1.27.2\r\n- kubelet v1.26.5\r\n\r\n#### Detection\r\n- v1.20.1",
+			want: []TextRange{{Introduced: "1.27.0", Fixed: "1.27.2"}, {Introduced: "1.26.0", Fixed: "1.26.5"}}},
+		{name: "no versions", text: "A security issue was discovered in kubelet", want: []TextRange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExtractTextRanges(tt.text))
+		})
+	}
+}
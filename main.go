@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb"
+	"github.com/aquasecurity/k8s-db-collector/collectors/cvedb/cve"
 	c "github.com/aquasecurity/k8s-db-collector/collectors/cvedb/utils"
 	"github.com/aquasecurity/k8s-db-collector/collectors/outdatedapi"
 	u "github.com/aquasecurity/k8s-db-collector/collectors/outdatedapi/utils"
@@ -22,8 +24,15 @@ const (
 )
 
 var (
-	target     = flag.String("target", "", "update target db (k8s-api,k8s-vulndb)")
-	githubRepo = flag.String("repo", "trivy-db-data", "github repo db (trivy-db-data,vuln-list-k8s)")
+	target       = flag.String("target", "", "update target db (k8s-api,k8s-vulndb), lint-mapping to check k8s vulndb components resolve or validate to check the k8s vulndb files given as arguments")
+	githubRepo   = flag.String("repo", "trivy-db-data", "github repo db (trivy-db-data,vuln-list-k8s)")
+	outputFormat = flag.String("output-format", cve.FormatJSON, "k8s vulndb cves output format (json,osv,yaml)")
+	overrides    = flag.String("overrides", "", "k8s vulndb curated cve overrides json file")
+	bySeverity   = flag.Bool("split-by-severity", false, "write k8s vulndb cves into one json file per severity")
+	minCves      = flag.Int("min-cves", 1, "fail the k8s vulndb update when fewer cves are collected")
+	nvdFeeds     = flag.String("nvd-feeds", "", "comma separated nvd json 2.0 feed files enriching k8s vulndb cves offline")
+	collectInfo  = flag.Bool("collector-info", false, "record the collector version and collection time in k8s vulndb cves")
+	cvssDetails  = flag.Bool("cvss-details", false, "record the cvss version, vector components and exploitability tags in k8s vulndb cves")
 )
 
 func main() {
@@ -34,6 +43,15 @@ func main() {
 
 func run() error {
 	flag.Parse()
+	if err := cve.ValidateOutputFormat(*outputFormat); err != nil {
+		return err
+	}
+	if *target == "lint-mapping" {
+		return lintMapping()
+	}
+	if *target == "validate" {
+		return validateFiles(flag.Args())
+	}
 	now := time.Now().UTC()
 	gc := &git.Config{}
 	debug := os.Getenv("VULN_LIST_DEBUG") != ""
@@ -74,7 +92,9 @@ func run() error {
 			return err
 		}
 	case "k8s-vulndb":
-		u := cvedb.NewUpdater()
+		u := cvedb.NewUpdater(cvedb.WithOutputFormat(*outputFormat), cvedb.WithOverrides(*overrides),
+			cvedb.WithNVDFeeds(nvdFeedPaths(*nvdFeeds)...), cvedb.WithSplitBySeverity(*bySeverity), cvedb.WithMinCves(*minCves),
+			cvedb.WithCollectorInfo(*collectInfo), cvedb.WithCvssDetails(*cvssDetails))
 		if err := u.Update(); err != nil {
 			return fmt.Errorf("k8s vulndb cves update error: %w", err)
 		}
@@ -110,3 +130,47 @@ func run() error {
 
 	return nil
 }
+
+// nvdFeedPaths split the comma separated nvd feed files flag
+func nvdFeedPaths(flagValue string) []string {
+	paths := make([]string, 0)
+	for _, p := range strings.Split(flagValue, ",") {
+		if p = strings.TrimSpace(p); len(p) > 0 {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// lintMapping report the k8s vulndb feed components the component mapping tables do not resolve
+func lintMapping() error {
+	unmapped, err := cve.LintMapping()
+	if err != nil {
+		return fmt.Errorf("k8s vulndb lint mapping error: %w", err)
+	}
+	for _, u := range unmapped {
+		log.Printf("unmapped component %s on cves %s", u.Component, strings.Join(u.CveIDs, ", "))
+	}
+	if len(unmapped) > 0 {
+		return fmt.Errorf("%d k8s vulndb components are not mapped to an upstream org", len(unmapped))
+	}
+	return nil
+}
+
+// validateFiles validate existing k8s vulndb files, reporting every invalid file before failing
+func validateFiles(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no k8s vulndb files to validate")
+	}
+	var invalid int
+	for _, p := range paths {
+		if err := cve.ValidateFile(p); err != nil {
+			log.Print(err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d k8s vulndb files are invalid", invalid, len(paths))
+	}
+	return nil
+}